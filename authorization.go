@@ -0,0 +1,48 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AuthorizationCredentials returns the credentials of a response consisting
+// of a single `Authorization` header with the given scheme (e.g., `Bearer`),
+// which is matched case-insensitively. It is meant for translating responses
+// to protocols which can only represent credentials of a certain kind.
+func (resp GetCredentialsResponse) AuthorizationCredentials(scheme string) (string, error) {
+	var authorization []string
+	for name, values := range resp.Headers {
+		if http.CanonicalHeaderKey(name) != "Authorization" {
+			return "", fmt.Errorf("header %q is not supported", name)
+		}
+		authorization = append(authorization, values...)
+	}
+	if len(authorization) != 1 {
+		return "", fmt.Errorf("expected a single Authorization header, got %d", len(authorization))
+	}
+
+	actual, credentials, _ := strings.Cut(authorization[0], " ")
+	if !strings.EqualFold(actual, scheme) {
+		return "", fmt.Errorf("authorization scheme %q is not supported, only %s is", actual, scheme)
+	}
+	credentials = strings.TrimSpace(credentials)
+	if credentials == "" {
+		return "", fmt.Errorf("empty %s credentials", scheme)
+	}
+	return credentials, nil
+}
@@ -18,20 +18,148 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ClientOptions represents options for invoking a credential helper.
+type ClientOptions struct {
+	// Env specifies environment variables to set for the credential helper,
+	// overriding any inherited from the current process.
+	Env map[string]string
+
+	// InheritEnv specifies the names of the environment variables of the
+	// current process passed to the credential helper.
+	//
+	// If nil, the credential helper inherits the full environment of the
+	// current process.
+	InheritEnv []string
+
+	// WorkingDirectory specifies the working directory of the credential
+	// helper.
+	//
+	// If empty, the credential helper runs in the working directory of the
+	// current process.
+	WorkingDirectory string
+
+	// Args specifies fixed arguments passed to the credential helper before
+	// the command (e.g., `get`).
+	Args []string
+
+	// Timeout specifies the maximum time a single invocation of the
+	// credential helper may take, in addition to any deadline of the context
+	// passed to [CredentialHelper.GetCredentials].
+	//
+	// If not set, invocations are only bounded by the context.
+	Timeout time.Duration
+
+	// CancelGracePeriod specifies how long to wait for the credential helper
+	// to exit after asking it to terminate (on cancellation or timeout) before
+	// killing it. It also bounds how long to wait for the output of the
+	// credential helper to be closed after it exited, in case it left behind
+	// child processes holding on to it.
+	//
+	// On Unix, the credential helper runs in its own process group and the
+	// whole group is terminated.
+	//
+	// If not set, CancelGracePeriod defaults to `DefaultCancelGracePeriod`.
+	CancelGracePeriod time.Duration
+
+	// MaxStdoutSize specifies the maximum number of bytes read from stdout
+	// of the credential helper. Larger responses are rejected with an error
+	// wrapping `ErrResponseTooLarge`.
+	//
+	// If not set, MaxStdoutSize defaults to `DefaultMaxStdoutSize`.
+	MaxStdoutSize int
+
+	// MaxStderrSize specifies the maximum number of bytes kept from stderr
+	// of the credential helper. If it writes more, only the last
+	// MaxStderrSize bytes are kept.
+	//
+	// If not set, MaxStderrSize defaults to `DefaultMaxStderrSize`.
+	MaxStderrSize int
+
+	// Strict specifies whether to reject responses failing
+	// [GetCredentialsResponse.Validate].
+	Strict bool
+
+	// ExpiryParsing specifies how to parse when credentials expire.
+	//
+	// If not set, ExpiryParsing defaults to `ExpiryParsingStrict`.
+	ExpiryParsing ExpiryParsing
+}
+
+const (
+	// DefaultMaxStdoutSize specifies the default maximum size of a response
+	// from a credential helper.
+	DefaultMaxStdoutSize = 1 << 20
+
+	// DefaultMaxStderrSize specifies the default number of bytes kept from
+	// stderr of a credential helper.
+	DefaultMaxStderrSize = 64 << 10
+)
+
+const (
+	// DefaultCancelGracePeriod specifies the default time to wait for a
+	// credential helper to terminate before killing it.
+	DefaultCancelGracePeriod = 5 * time.Second
 )
 
 // NewClient returns a new [CredentialHelper] invoking the provided path following
 // the protocol for Bazel Credential Helpers.
+//
+// Errors returned from invoking the credential helper are of type
+// [*HelperError].
 func NewClient(credentialHelperPath string) (CredentialHelper, error) {
+	return NewClientWithOptions(credentialHelperPath, ClientOptions{})
+}
+
+// NewClientWithOptions is like [NewClient], but allows customizing how the
+// credential helper is invoked.
+//
+// Relative paths are resolved against the working directory of the current
+// process, even if [ClientOptions.WorkingDirectory] is set.
+func NewClientWithOptions(credentialHelperPath string, options ClientOptions) (CredentialHelper, error) {
+	if options.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative, got %v", options.Timeout)
+	}
+	if options.CancelGracePeriod < 0 {
+		return nil, fmt.Errorf("cancel grace period must not be negative, got %v", options.CancelGracePeriod)
+	} else if options.CancelGracePeriod == 0 {
+		options.CancelGracePeriod = DefaultCancelGracePeriod
+	}
+	if options.MaxStdoutSize < 0 {
+		return nil, fmt.Errorf("max stdout size must not be negative, got %v", options.MaxStdoutSize)
+	} else if options.MaxStdoutSize == 0 {
+		options.MaxStdoutSize = DefaultMaxStdoutSize
+	}
+	if options.MaxStderrSize < 0 {
+		return nil, fmt.Errorf("max stderr size must not be negative, got %v", options.MaxStderrSize)
+	} else if options.MaxStderrSize == 0 {
+		options.MaxStderrSize = DefaultMaxStderrSize
+	}
+
 	path, err := exec.LookPath(credentialHelperPath)
+	if err == nil && !filepath.IsAbs(path) {
+		path, err = filepath.Abs(path)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("could not lookup credential helper %q: %w", credentialHelperPath, err)
+		return nil, &HelperError{
+			Path:     credentialHelperPath,
+			Phase:    PhaseLookup,
+			ExitCode: -1,
+			Err:      err,
+		}
 	}
 
 	c := &client{
 		credentialHelperPath: path,
+		options:              options,
 	}
 	return c, nil
 }
@@ -40,45 +168,186 @@ type client struct {
 	CredentialHelperBase
 
 	credentialHelperPath string
+	options              ClientOptions
 }
 
 // GetCredentials invokes the specified credential helper to fetch credentials.
 func (c *client) GetCredentials(ctx context.Context, request *GetCredentialsRequest, extraParameters ...string) (*GetCredentialsResponse, error) {
-	var response GetCredentialsResponse
-	if err := invoke(ctx, c.credentialHelperPath, request, &response, append([]string{"get"}, extraParameters...)...); err != nil {
+	response := responseWithExpiryParsing{mode: c.options.ExpiryParsing}
+	if err := invoke(ctx, c, "get", request, &response, extraParameters...); err != nil {
+		return nil, err
+	}
+	if err := c.validate(&response.GetCredentialsResponse); err != nil {
 		return nil, err
 	}
-	return &response, nil
+	return &response.GetCredentialsResponse, nil
 }
 
-func invoke[RequestT any, ResponseT any](ctx context.Context, credentialHelperPath string, request *RequestT, response *ResponseT, extraArgs ...string) error {
+// responseWithExpiryParsing decodes a [GetCredentialsResponse] using a
+// specific [ExpiryParsing].
+type responseWithExpiryParsing struct {
+	GetCredentialsResponse
+
+	mode ExpiryParsing
+}
+
+func (r *responseWithExpiryParsing) UnmarshalJSON(data []byte) error {
+	return r.GetCredentialsResponse.unmarshalJSON(data, r.mode)
+}
+
+// validate checks response if the client is strict.
+func (c *client) validate(response *GetCredentialsResponse) error {
+	if !c.options.Strict {
+		return nil
+	}
+	if err := response.Validate(); err != nil {
+		return &HelperError{
+			Path:     c.credentialHelperPath,
+			Command:  "get",
+			Phase:    PhaseResponse,
+			ExitCode: 0,
+			Err:      err,
+		}
+	}
+	return nil
+}
+
+// environ returns the environment for the credential helper, or nil if it
+// should inherit the environment of the current process unchanged.
+func (c *client) environ() []string {
+	if c.options.InheritEnv == nil && len(c.options.Env) == 0 {
+		return nil
+	}
+
+	var env []string
+	if c.options.InheritEnv == nil {
+		env = os.Environ()
+	} else {
+		for _, name := range c.options.InheritEnv {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	}
+
+	// Later entries take precedence over earlier ones.
+	names := make([]string, 0, len(c.options.Env))
+	for name := range c.options.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+c.options.Env[name])
+	}
+
+	// Ensure an empty environment is not mistaken for inheriting one.
+	if env == nil {
+		env = []string{}
+	}
+	return env
+}
+
+func invoke[RequestT any, ResponseT any](ctx context.Context, c *client, command string, request *RequestT, response *ResponseT, extraArgs ...string) error {
 	stdin, err := json.Marshal(request)
 	if err != nil {
 		return err
 	}
 
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
+	start := time.Now()
+	stdout, stderr, err := c.run(ctx, command, stdin, append([]string{command}, extraArgs...))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(stdout, response); err != nil {
+		return &HelperError{
+			Path:            c.credentialHelperPath,
+			Command:         command,
+			Phase:           PhaseResponse,
+			ExitCode:        0,
+			Stderr:          stderr.Bytes(),
+			StderrTruncated: stderr.truncated,
+			Elapsed:         time.Since(start),
+			Err:             err,
+		}
+	}
+
+	return nil
+}
+
+// run runs the credential helper with args following the fixed arguments,
+// writing stdin to its standard input, and returns its standard output and
+// the tail of its standard error. command identifies the invocation in errors.
+//
+// If the credential helper exits with a non-zero exit code, its standard
+// output is returned along with the error.
+func (c *client) run(ctx context.Context, command string, stdin []byte, args []string) ([]byte, *tailBuffer, error) {
+	if c.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+	}
+
+	stdout := &headBuffer{limit: c.options.MaxStdoutSize}
+	stderr := &tailBuffer{limit: c.options.MaxStderrSize}
 
-	cmd := exec.CommandContext(ctx, credentialHelperPath, extraArgs...)
+	cmd := exec.CommandContext(ctx, c.credentialHelperPath, append(append([]string{}, c.options.Args...), args...)...)
+	cmd.Dir = c.options.WorkingDirectory
+	cmd.Env = c.environ()
+	cmd.WaitDelay = c.options.CancelGracePeriod
+	setupProcessGroup(cmd)
 	cmd.Stdin = bytes.NewBuffer(stdin)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	helperErr := func(phase HelperErrorPhase, err error) *HelperError {
+		return &HelperError{
+			Path:            c.credentialHelperPath,
+			Command:         command,
+			Phase:           phase,
+			ExitCode:        cmd.ProcessState.ExitCode(),
+			Stderr:          stderr.Bytes(),
+			StderrTruncated: stderr.truncated,
+			Elapsed:         time.Since(start),
+			Err:             err,
+		}
+	}
 
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("could not start credential helper: %w", err)
+		return nil, stderr, helperErr(PhaseStart, err)
+	}
+	err := cmd.Wait()
+	if ctx.Err() != nil {
+		killProcessGroup(cmd)
+	}
+	if errors.Is(err, exec.ErrWaitDelay) && ctx.Err() == nil {
+		// The credential helper exited successfully, but left behind child
+		// processes holding on to its output. What it wrote is all there is.
+		err = nil
 	}
-	if err := cmd.Wait(); err != nil {
+	if err != nil {
+		phase := PhaseExit
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			phase = PhaseCanceled
+			if errors.Is(ctxErr, context.DeadlineExceeded) {
+				phase = PhaseTimeout
+			}
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitErr.Stderr = stderr.Bytes()
 		}
-		return fmt.Errorf("error running credential helper: %w", err)
+		e := helperErr(phase, err)
+		if phase == PhaseExit {
+			e.Protocol = parseProtocolError(e.Stderr)
+			return stdout.Bytes(), stderr, e
+		}
+		return nil, stderr, e
 	}
 
-	stdoutBytes := stdout.Bytes()
-	if err := json.Unmarshal(stdoutBytes, response); err != nil {
-		return fmt.Errorf("could not read response from credential helper: %w", err)
+	if stdout.exceeded {
+		return nil, stderr, helperErr(PhaseResponse, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, c.options.MaxStdoutSize))
 	}
 
-	return nil
+	return stdout.Bytes(), stderr, nil
 }
@@ -18,8 +18,14 @@ package credentialhelper_test
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/EngFlow/credential-helper-go"
 
@@ -86,16 +92,47 @@ func TestClient_NotExecutable(t *testing.T) {
 		return
 	}
 
-	if err = os.WriteFile("testdata/not-executable-copy.sh", data, 0644); err != nil {
+	path := filepath.Join(t.TempDir(), "not-executable-copy.sh")
+	if err = os.WriteFile(path, data, 0644); err != nil {
 		t.Fatal(err)
 		return
 	}
 
-	response, err := runCredentialHelper("testdata/not-executable-copy.sh")
+	response, err := runCredentialHelper(path)
 	assert.ErrorContains(t, err, "could not lookup credential helper")
-	assert.ErrorContains(t, err, "testdata/not-executable-copy.sh")
+	assert.ErrorContains(t, err, path)
 	assert.ErrorContains(t, err, "permission denied")
 	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseLookup, helperErr.Phase)
+	}
+}
+
+func TestClient_StartFailure(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/echo-invocation.sh",
+		credentialhelper.ClientOptions{
+			WorkingDirectory: filepath.Join(t.TempDir(), "missing"),
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.Error(t, err)
+	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseStart, helperErr.Phase)
+		assert.NotZero(t, helperErr.Elapsed)
+	}
 }
 
 func TestClient_WrongExitCode(t *testing.T) {
@@ -110,10 +147,393 @@ func TestClient_WrongExitCodeWithStderr(t *testing.T) {
 	assert.ErrorContains(t, err, "error running credential helper")
 	assert.ErrorContains(t, err, "exit status 1")
 	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseExit, helperErr.Phase)
+		assert.Equal(t, "get", helperErr.Command)
+		assert.Equal(t, 1, helperErr.ExitCode)
+		assert.Equal(t, "Hello, World!\n", string(helperErr.Stderr))
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	client, err := credentialhelper.NewClient("testdata/sleep.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	response, err := client.GetCredentials(
+		ctx,
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.ErrorContains(t, err, "timed out")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseTimeout, helperErr.Phase)
+		assert.Equal(t, -1, helperErr.ExitCode)
+	}
+}
+
+func TestClient_Canceled(t *testing.T) {
+	client, err := credentialhelper.NewClient("testdata/sleep.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	response, err := client.GetCredentials(
+		ctx,
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseCanceled, helperErr.Phase)
+	}
 }
 
 func TestClient_InvalidResponse(t *testing.T) {
 	response, err := runCredentialHelper("testdata/invalid-response.sh")
 	assert.ErrorContains(t, err, "could not read response from credential helper")
 	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseResponse, helperErr.Phase)
+		assert.Equal(t, 0, helperErr.ExitCode)
+	}
+}
+
+func TestClient_WithOptions(t *testing.T) {
+	t.Setenv("FOO", "inherited")
+	t.Setenv("BAR", "inherited")
+
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/echo-invocation.sh",
+		credentialhelper.ClientOptions{
+			Env: map[string]string{
+				"BAR": "overridden",
+			},
+			WorkingDirectory: "testdata",
+			Args:             []string{"--leading", "arg"},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		},
+		"extra")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{
+				"args": {"--leading arg get extra"},
+				"pwd":  {"testdata"},
+				"foo":  {"inherited"},
+				"bar":  {"overridden"},
+			},
+		},
+		response)
+}
+
+func TestClient_WithInheritEnv(t *testing.T) {
+	t.Setenv("FOO", "inherited")
+	t.Setenv("BAR", "inherited")
+
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/echo-invocation.sh",
+		credentialhelper.ClientOptions{
+			InheritEnv: []string{"PATH", "FOO"},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"inherited"}, response.Headers["foo"])
+	assert.Equal(t, []string{"unset"}, response.Headers["bar"])
+}
+
+func TestClient_WithTimeout(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/sleep.sh",
+		credentialhelper.ClientOptions{
+			Timeout: 100 * time.Millisecond,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.ErrorContains(t, err, "timed out")
+	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseTimeout, helperErr.Phase)
+	}
+}
+
+func TestClient_CancelKillsProcessGroup(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/spawns-grandchild.sh",
+		credentialhelper.ClientOptions{
+			Timeout:           100 * time.Millisecond,
+			CancelGracePeriod: 100 * time.Millisecond,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	start := time.Now()
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		},
+		pidFile)
+	assert.ErrorContains(t, err, "timed out")
+	assert.Nil(t, response)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	assertExited(t, readPID(t, pidFile))
+}
+
+func TestClient_CancelEscalatesToKill(t *testing.T) {
+	gracePeriod := 300 * time.Millisecond
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/ignores-term.sh",
+		credentialhelper.ClientOptions{
+			Timeout:           100 * time.Millisecond,
+			CancelGracePeriod: gracePeriod,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	start := time.Now()
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		},
+		dir)
+	elapsed := time.Since(start)
+	assert.ErrorContains(t, err, "timed out")
+	assert.Nil(t, response)
+
+	// The helper survived SIGTERM, so it was only killed once the grace
+	// period passed.
+	assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond+gracePeriod)
+	assert.Less(t, elapsed, 5*time.Second)
+	term, err := os.ReadFile(filepath.Join(dir, "term"))
+	assert.NoError(t, err)
+	assert.Equal(t, "TERM\n", string(term))
+
+	assertExited(t, readPID(t, filepath.Join(dir, "pid")))
+}
+
+// readPID reads the PID a test script wrote to path.
+func readPID(t *testing.T, path string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
+
+// assertExited asserts that the process with the given PID exits soon.
+func assertExited(t *testing.T, pid int) {
+	t.Helper()
+
+	// The process is reaped asynchronously, so give it some time.
+	assert.Eventually(
+		t,
+		func() bool { return syscall.Kill(pid, 0) == syscall.ESRCH },
+		5*time.Second,
+		10*time.Millisecond)
+}
+
+func TestClient_DoesNotWaitForInheritedPipes(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/leaves-grandchild.sh",
+		credentialhelper.ClientOptions{
+			CancelGracePeriod: 100 * time.Millisecond,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	start := time.Now()
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		},
+		pidFile)
+	assert.NoError(t, err)
+	assert.Equal(t, &credentialhelper.GetCredentialsResponse{}, response)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Do not leave the grandchild running after the test.
+	syscall.Kill(readPID(t, pidFile), syscall.SIGKILL)
+}
+
+func TestClient_ResponseTooLarge(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/large-response.sh",
+		credentialhelper.ClientOptions{
+			MaxStdoutSize: 1024,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.ErrorIs(t, err, credentialhelper.ErrResponseTooLarge)
+	assert.ErrorContains(t, err, "could not read response from credential helper")
+	assert.Nil(t, response)
+}
+
+func TestClient_StderrTruncated(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/large-stderr.sh",
+		credentialhelper.ClientOptions{
+			MaxStderrSize: 20,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.ErrorContains(t, err, "exit status 1")
+	assert.Nil(t, response)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.True(t, helperErr.StderrTruncated)
+		assert.Equal(t, "\nline 999\nline 1000\n", string(helperErr.Stderr))
+	}
+}
+
+func TestClient_Strict(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/invalid-headers.sh",
+		credentialhelper.ClientOptions{
+			Strict: true,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, credentialhelper.ErrInvalidResponse)
+	assert.ErrorContains(t, err, "could not read response from credential helper")
+	assert.ErrorContains(t, err, `header "Bad Name": name is not a valid token`)
+	assert.ErrorContains(t, err, `header "Injected": value 0 contains control character '\r'`)
+}
+
+func TestClient_NotStrict(t *testing.T) {
+	response, err := runCredentialHelper("testdata/invalid-headers.sh")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"value"}, response.Headers["Bad Name"])
+}
+
+func TestClient_ExpiryParsing(t *testing.T) {
+	response, err := runCredentialHelper("testdata/epoch-expires.sh")
+	assert.ErrorContains(t, err, "could not read response from credential helper")
+	assert.Nil(t, response)
+
+	client, err := credentialhelper.NewClientWithOptions(
+		"testdata/epoch-expires.sh",
+		credentialhelper.ClientOptions{
+			ExpiryParsing: credentialhelper.ExpiryParsingLenient,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err = client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if assert.NoError(t, err) && assert.NotNil(t, response.Expires) {
+		assert.True(t, time.Unix(981173106, 0).Equal(*response.Expires))
+	}
+}
+
+func TestProgram(t *testing.T) {
+	program, err := credentialhelper.NewProgram(
+		"sh",
+		credentialhelper.ClientOptions{
+			Args: []string{"-c", `printf '%s:' "$0"; cat; [ "$0" = ok ]`},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, filepath.IsAbs(program.Path()))
+
+	stdout, err := program.Run(context.Background(), []byte("input"), "ok")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok:input", string(stdout))
+
+	stdout, err = program.Run(context.Background(), []byte("input"), "fail")
+	var helperErr *credentialhelper.HelperError
+	if assert.ErrorAs(t, err, &helperErr) {
+		assert.Equal(t, credentialhelper.PhaseExit, helperErr.Phase)
+		assert.Equal(t, "fail", helperErr.Command)
+		assert.Equal(t, 1, helperErr.ExitCode)
+	}
+	assert.Equal(t, "fail:input", string(stdout))
 }
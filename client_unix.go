@@ -0,0 +1,38 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package credentialhelper
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setupProcessGroup makes the credential helper the leader of a new process
+// group and asks the whole group to terminate when the command is canceled.
+func setupProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
+
+// killProcessGroup kills whatever is left of the process group of the
+// credential helper.
+func killProcessGroup(cmd *exec.Cmd) {
+	// The group might already be gone, so the error is deliberately ignored.
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
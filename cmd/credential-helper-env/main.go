@@ -0,0 +1,37 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command credential-helper-env is a credential helper returning headers
+// configured in environment variables.
+//
+// See [credentialhelperenv] for how to configure headers.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperenv"
+)
+
+func main() {
+	helper, err := credentialhelperenv.New(os.Environ())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	credentialhelper.StartCredentialHelper(helper)
+}
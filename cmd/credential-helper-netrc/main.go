@@ -0,0 +1,48 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command credential-helper-netrc is a credential helper returning `Basic`
+// credentials from the `.netrc` file of the current user.
+//
+// The file is located as described by [credentialhelpernetrc.DefaultPath]. If
+// it does not exist, no credentials are returned for any host.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelpernetrc"
+)
+
+func main() {
+	path, err := credentialhelpernetrc.DefaultPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	netrc, err := credentialhelpernetrc.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		netrc = &credentialhelpernetrc.Netrc{}
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	credentialhelper.StartCredentialHelper(credentialhelpernetrc.New(netrc))
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"text/tabwriter"
+)
+
+// Command is an additional command of a credential helper started with
+// [StartCredentialHelper], served alongside the commands of the Helper
+// Protocol (e.g., `login` or `version`).
+type Command struct {
+	// Name is the name of the command on the command line.
+	Name string
+
+	// Summary is a one-line description of the command, shown in the list of
+	// commands.
+	Summary string
+
+	// Usage describes the arguments of the command (e.g., `[flags] <uri>`),
+	// shown in the help of the command.
+	Usage string
+
+	// Description is an optional longer description of the command, shown in
+	// the help of the command.
+	Description string
+
+	// Flags, if set, holds the flags of the command. They are parsed before
+	// calling Run and listed in the help of the command.
+	Flags *flag.FlagSet
+
+	// Run runs the command with the arguments remaining after parsing Flags,
+	// and returns the exit code of the process.
+	Run func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
+}
+
+// commandSet holds the commands of a credential helper, in the order they are
+// listed in the help.
+type commandSet struct {
+	procName string
+	commands []*Command
+}
+
+func newCommandSet(procName string, commands ...[]*Command) *commandSet {
+	s := &commandSet{
+		procName: path.Base(procName),
+	}
+	for _, group := range commands {
+		for _, command := range group {
+			if command.Name == "" || command.Run == nil {
+				panic("credential helper commands must have a name and Run")
+			}
+			if s.lookup(command.Name) != nil {
+				panic(fmt.Sprintf("credential helper command %q is defined more than once", command.Name))
+			}
+			s.commands = append(s.commands, command)
+		}
+	}
+	return s
+}
+
+func (s *commandSet) lookup(name string) *Command {
+	for _, command := range s.commands {
+		if command.Name == name {
+			return command
+		}
+	}
+	return nil
+}
+
+func (s *commandSet) run(ctx context.Context, stdin io.Reader, stdout io.Writer, stderr io.Writer, args []string) int {
+	if len(args) == 0 {
+		s.printHelp(stderr)
+		return 1
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		return s.runHelp(stdout, stderr, args[1:])
+	}
+
+	command := s.lookup(args[0])
+	if command == nil {
+		fmt.Fprintln(stderr, "Unknown command '"+args[0]+"'")
+		fmt.Fprintln(stderr, "")
+		s.printHelp(stderr)
+		return 1
+	}
+
+	args = args[1:]
+	if command.Flags != nil {
+		command.Flags.SetOutput(stderr)
+		command.Flags.Usage = func() {
+			s.printCommandHelp(stderr, command)
+		}
+		if err := command.Flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+			return 0
+		} else if err != nil {
+			return 1
+		}
+		args = command.Flags.Args()
+	}
+
+	return command.Run(ctx, args, stdin, stdout, stderr)
+}
+
+func (s *commandSet) runHelp(stdout io.Writer, stderr io.Writer, args []string) int {
+	switch len(args) {
+	case 0:
+		s.printHelp(stdout)
+		return 0
+
+	case 1:
+		command := s.lookup(args[0])
+		if command == nil {
+			fmt.Fprintln(stderr, "Unknown command '"+args[0]+"'")
+			return 1
+		}
+		s.printCommandHelp(stdout, command)
+		return 0
+
+	default:
+		s.printHelp(stderr)
+		return 1
+	}
+}
+
+func (s *commandSet) printHelp(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  "+s.procName+" [--timeout=<duration>] <command> [arguments]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, command := range s.commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", command.Name, command.Summary)
+	}
+	fmt.Fprintf(tw, "  %s\t%s\n", "help", "Prints help about a command")
+	tw.Flush()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run '"+s.procName+" help <command>' for more information about a command.")
+}
+
+func (s *commandSet) printCommandHelp(w io.Writer, command *Command) {
+	fmt.Fprintln(w, "Usage:")
+	usage := "  " + s.procName + " " + command.Name
+	if command.Usage != "" {
+		usage += " " + command.Usage
+	}
+	fmt.Fprintln(w, usage)
+
+	if command.Summary != "" || command.Description != "" {
+		fmt.Fprintln(w, "")
+		if command.Summary != "" {
+			fmt.Fprintln(w, command.Summary)
+		}
+		if command.Description != "" {
+			fmt.Fprintln(w, command.Description)
+		}
+	}
+
+	if command.Flags != nil {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Flags:")
+		command.Flags.SetOutput(w)
+		command.Flags.PrintDefaults()
+	}
+}
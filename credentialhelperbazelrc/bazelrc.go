@@ -0,0 +1,345 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelperbazelrc reads the credential helpers configured for
+// Bazel in `.bazelrc` files, so that other tools can use the same credentials
+// as Bazel.
+package credentialhelperbazelrc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultSystemRC is the path of the system-wide rc file read by Bazel.
+	DefaultSystemRC = "/etc/bazel.bazelrc"
+
+	// DefaultCommand is the Bazel command whose options are read by default.
+	DefaultCommand = "build"
+
+	// workspacePlaceholder is replaced by the workspace directory in paths.
+	workspacePlaceholder = "%workspace%"
+)
+
+// commandParents maps Bazel commands to the command they inherit options
+// from. Like in Bazel, `cquery` inherits from `test` so that test options
+// affect the configuration it queries.
+var commandParents = map[string]string{
+	"aquery":         "build",
+	"coverage":       "test",
+	"cquery":         "test",
+	"mobile-install": "build",
+	"print_action":   "build",
+	"run":            "build",
+	"test":           "build",
+}
+
+// Options represents options for reading rc files.
+type Options struct {
+	// Workspace specifies the root directory of the Bazel workspace. It is
+	// substituted for `%workspace%` and used to resolve relative paths.
+	//
+	// If empty, the workspace rc file is not read and paths must not
+	// reference `%workspace%`.
+	Workspace string
+
+	// HomeDirectory specifies the directory containing the user's rc file.
+	//
+	// If empty, it defaults to [os.UserHomeDir].
+	HomeDirectory string
+
+	// SystemRC specifies the path of the system-wide rc file.
+	//
+	// If empty, it defaults to [DefaultSystemRC].
+	SystemRC string
+
+	// NoSystemRC, NoWorkspaceRC and NoHomeRC skip the respective rc files,
+	// like Bazel's `--nosystem_rc`, `--noworkspace_rc` and `--nohome_rc`
+	// startup options.
+	NoSystemRC    bool
+	NoWorkspaceRC bool
+	NoHomeRC      bool
+
+	// RCFiles specifies additional rc files read after the default ones, like
+	// Bazel's `--bazelrc` startup option. Unlike the default rc files, they
+	// must exist.
+	RCFiles []string
+
+	// Command specifies the Bazel command whose options are read, along with
+	// those of the commands it inherits from and the `common` and `always`
+	// sections.
+	//
+	// If empty, it defaults to [DefaultCommand].
+	Command string
+}
+
+// rcLine represents a line of an rc file applying options to a command.
+type rcLine struct {
+	command string
+	args    []string
+}
+
+// rcReader reads rc files and the files they import.
+type rcReader struct {
+	workspace string
+	lines     []rcLine
+
+	// importing holds the files currently being read, to detect import
+	// cycles.
+	importing map[string]bool
+}
+
+// readRCFiles reads the rc files selected by options in the order Bazel
+// reads them, and returns the arguments for the command selected by options
+// in the order Bazel applies them.
+func readRCFiles(options Options) ([]string, error) {
+	r := &rcReader{
+		workspace: options.Workspace,
+		importing: make(map[string]bool),
+	}
+
+	if !options.NoSystemRC {
+		systemRC := options.SystemRC
+		if systemRC == "" {
+			systemRC = DefaultSystemRC
+		}
+		if err := r.readFile(systemRC, true); err != nil {
+			return nil, err
+		}
+	}
+	if !options.NoWorkspaceRC && options.Workspace != "" {
+		if err := r.readFile(filepath.Join(options.Workspace, ".bazelrc"), true); err != nil {
+			return nil, err
+		}
+	}
+	if !options.NoHomeRC {
+		home := options.HomeDirectory
+		if home == "" {
+			var err error
+			if home, err = os.UserHomeDir(); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.readFile(filepath.Join(home, ".bazelrc"), true); err != nil {
+			return nil, err
+		}
+	}
+	for _, rcFile := range options.RCFiles {
+		path, err := r.resolvePath(rcFile, "")
+		if err != nil {
+			return nil, err
+		}
+		if err := r.readFile(path, false); err != nil {
+			return nil, err
+		}
+	}
+
+	command := options.Command
+	if command == "" {
+		command = DefaultCommand
+	}
+
+	// Options of more specific commands take precedence regardless of the
+	// order of the lines, so lines are grouped by command, starting with the
+	// least specific one. `always` and `common` lines form a single group
+	// applied in file order.
+	commands := []string{command}
+	for parent, ok := commandParents[command]; ok; parent, ok = commandParents[parent] {
+		commands = append([]string{parent}, commands...)
+	}
+	commands = append([]string{"common"}, commands...)
+
+	var args []string
+	for _, command := range commands {
+		for _, line := range r.lines {
+			if commandGroup(line.command) == command {
+				args = append(args, line.args...)
+			}
+		}
+	}
+	return args, nil
+}
+
+// commandGroup returns the command whose group a line for command belongs
+// to.
+func commandGroup(command string) string {
+	if command == "always" {
+		return "common"
+	}
+	return command
+}
+
+// readFile reads the rc file at path. If optional is set, it is ignored if it
+// does not exist.
+func (r *rcReader) readFile(path string, optional bool) error {
+	if r.importing[path] {
+		return fmt.Errorf("import cycle detected at %q", path)
+	}
+
+	f, err := os.Open(path)
+	if optional && errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r.importing[path] = true
+	defer delete(r.importing, path)
+
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	var logical strings.Builder
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		// A trailing backslash continues the line.
+		if strings.HasSuffix(line, `\`) {
+			logical.WriteString(strings.TrimSuffix(line, `\`))
+			continue
+		}
+		logical.WriteString(line)
+
+		if err := r.readLine(path, logical.String()); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		logical.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if logical.Len() > 0 {
+		if err := r.readLine(path, logical.String()); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+	}
+	return nil
+}
+
+// readLine interprets a single logical line of the rc file at path.
+func (r *rcReader) readLine(path string, line string) error {
+	words, err := tokenize(line)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	switch words[0] {
+	case "import", "try-import":
+		if len(words) != 2 {
+			return fmt.Errorf("%s expects a single path, got %q", words[0], words[1:])
+		}
+		importPath, err := r.resolvePath(words[1], filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		return r.readFile(importPath, words[0] == "try-import")
+	}
+
+	// Options for a configuration (e.g., `build:ci`) only apply when that
+	// configuration is selected with `--config`, which is not supported.
+	if strings.Contains(words[0], ":") {
+		return nil
+	}
+
+	r.lines = append(r.lines, rcLine{
+		command: words[0],
+		args:    words[1:],
+	})
+	return nil
+}
+
+// resolvePath expands `%workspace%` in path and makes it absolute. Relative
+// paths are resolved against the workspace if set, and dir otherwise.
+func (r *rcReader) resolvePath(path string, dir string) (string, error) {
+	if strings.Contains(path, workspacePlaceholder) {
+		if r.workspace == "" {
+			return "", fmt.Errorf("path %q references %s, but no workspace is set", path, workspacePlaceholder)
+		}
+		path = strings.ReplaceAll(path, workspacePlaceholder, r.workspace)
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	if r.workspace != "" {
+		dir = r.workspace
+	}
+	return filepath.Abs(filepath.Join(dir, path))
+}
+
+// tokenize splits line into words like a shell would, honoring single and
+// double quotes and backslash escapes. A `#` at the start of a word starts a
+// comment extending to the end of the line.
+func tokenize(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote byte
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' && i+1 < len(line) {
+				i++
+				word.WriteByte(line[i])
+			} else {
+				word.WriteByte(c)
+			}
+
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+
+		case c == '\\':
+			if i+1 < len(line) {
+				i++
+				word.WriteByte(line[i])
+			}
+			inWord = true
+
+		case c == ' ' || c == '\t' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case c == '#' && !inWord:
+			return words, nil
+
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperbazelrc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/EngFlow/credential-helper-go/credentialhelperbazelrc"
+)
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testOptions returns options reading only rc files in a fresh workspace and
+// home directory.
+func testOptions(t *testing.T) credentialhelperbazelrc.Options {
+	return credentialhelperbazelrc.Options{
+		Workspace:     t.TempDir(),
+		HomeDirectory: t.TempDir(),
+		NoSystemRC:    true,
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := credentialhelperbazelrc.Load(testOptions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Helpers) != 0 {
+		t.Errorf("expected no helpers, got %v", cfg.Helpers)
+	}
+	if cfg.Timeout != credentialhelperbazelrc.DefaultTimeout {
+		t.Errorf("expected default timeout, got %v", cfg.Timeout)
+	}
+	if cfg.CacheDuration != credentialhelperbazelrc.DefaultCacheDuration {
+		t.Errorf("expected default cache duration, got %v", cfg.CacheDuration)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	options := testOptions(t)
+	options.SystemRC = writeFile(t, t.TempDir(), "system.bazelrc", `
+common --credential_helper=/system/helper
+`)
+	options.NoSystemRC = false
+	writeFile(t, options.Workspace, ".bazelrc", `
+# Comments are ignored.
+build --credential_helper=*.example.com=%workspace%/tools/helper \
+    --credential_helper_timeout=5s # trailing comment
+build:ci --credential_helper=ci-helper
+query --credential_helper=query-helper
+import %workspace%/tools/credentials.bazelrc
+try-import %workspace%/user.bazelrc
+`)
+	writeFile(t, options.Workspace, "tools/credentials.bazelrc", `
+build --credential_helper example.org=relative/helper
+always --credential_helper_cache_duration=1d
+`)
+	writeFile(t, options.HomeDirectory, ".bazelrc", `
+build "--credential_helper=foo.example.com=/path with spaces/helper"
+`)
+	options.RCFiles = []string{writeFile(t, t.TempDir(), "extra.bazelrc", `
+build --credential_helper_timeout=20s
+`)}
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &credentialhelperbazelrc.Config{
+		Workspace: options.Workspace,
+		Helpers: []credentialhelperbazelrc.Helper{
+			{Path: "/system/helper"},
+			{Scope: "*.example.com", Path: filepath.Join(options.Workspace, "tools/helper")},
+			{Scope: "example.org", Path: filepath.Join(options.Workspace, "relative/helper")},
+			{Scope: "foo.example.com", Path: "/path with spaces/helper"},
+		},
+		Timeout:       20 * time.Second,
+		CacheDuration: 24 * time.Hour,
+	}
+	if diff := cmp.Diff(want, cfg); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadMoreSpecificCommandsTakePrecedence(t *testing.T) {
+	options := testOptions(t)
+	options.Command = "test"
+	writeFile(t, options.Workspace, ".bazelrc", `
+test --credential_helper_timeout=3s
+build --credential_helper_timeout=2s
+common --credential_helper_timeout=1s
+run --credential_helper_timeout=4s
+`)
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 3*time.Second {
+		t.Errorf("expected timeout of test command, got %v", cfg.Timeout)
+	}
+}
+
+func TestLoadCommonAndAlwaysInFileOrder(t *testing.T) {
+	options := testOptions(t)
+	writeFile(t, options.Workspace, ".bazelrc", `
+common --credential_helper_timeout=1s
+always --credential_helper_timeout=2s
+common --credential_helper_timeout=3s
+`)
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 3*time.Second {
+		t.Errorf("expected timeout of last line, got %v", cfg.Timeout)
+	}
+}
+
+func TestLoadCqueryInheritsTest(t *testing.T) {
+	options := testOptions(t)
+	options.Command = "cquery"
+	writeFile(t, options.Workspace, ".bazelrc", `
+build --credential_helper_timeout=1s
+test --credential_helper_timeout=2s
+`)
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 2*time.Second {
+		t.Errorf("expected timeout of test command, got %v", cfg.Timeout)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, rc := range map[string]string{
+		"missing import":        "import %workspace%/missing.bazelrc",
+		"import cycle":          "import %workspace%/.bazelrc",
+		"invalid scope":         "build --credential_helper=*.=helper",
+		"missing path":          "build --credential_helper=example.com=",
+		"missing value":         "build --credential_helper_timeout",
+		"invalid timeout":       "build --credential_helper_timeout=soon",
+		"negative duration":     "build --credential_helper_cache_duration=-1s",
+		"unterminated quote":    "build '--credential_helper=helper",
+		"import without a path": "import",
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := testOptions(t)
+			writeFile(t, options.Workspace, ".bazelrc", rc)
+
+			if _, err := credentialhelperbazelrc.Load(options); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadMissingRCFile(t *testing.T) {
+	options := testOptions(t)
+	options.RCFiles = []string{filepath.Join(t.TempDir(), "missing.bazelrc")}
+
+	if _, err := credentialhelperbazelrc.Load(options); err == nil {
+		t.Error("expected error for missing --bazelrc file")
+	}
+}
+
+func TestLoadWithoutWorkspace(t *testing.T) {
+	options := testOptions(t)
+	options.Workspace = ""
+	writeFile(t, options.HomeDirectory, ".bazelrc", "build --credential_helper=%workspace%/helper")
+
+	if _, err := credentialhelperbazelrc.Load(options); err == nil {
+		t.Error("expected error for %workspace% without workspace")
+	}
+}
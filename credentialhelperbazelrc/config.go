@@ -0,0 +1,239 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperbazelrc
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelpercache"
+	"github.com/EngFlow/credential-helper-go/credentialhelperrouter"
+)
+
+const (
+	// DefaultTimeout is the default of Bazel's `--credential_helper_timeout`.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultCacheDuration is the default of Bazel's
+	// `--credential_helper_cache_duration`.
+	DefaultCacheDuration = credentialhelpercache.DefaultCacheDuration
+)
+
+// bazelDurationPattern matches durations in the format accepted by Bazel
+// which [time.ParseDuration] does not support (e.g., `1d`).
+var bazelDurationPattern = regexp.MustCompile(`^(\d+)(d|h|m|s|ms)$`)
+
+// Helper represents a credential helper configured with Bazel's
+// `--credential_helper` flag.
+type Helper struct {
+	// Scope is the host pattern the helper is used for (see
+	// [credentialhelperrouter.ParsePattern]), or empty for the default
+	// helper.
+	Scope string
+
+	// Path is the path of the credential helper, with `%workspace%`
+	// expanded. Paths without separator are looked up in `PATH`.
+	Path string
+}
+
+// Config represents the credential helper configuration read from rc files.
+type Config struct {
+	// Workspace is the root directory of the Bazel workspace, if any.
+	Workspace string
+
+	// Helpers are the configured credential helpers, in the order they were
+	// specified.
+	Helpers []Helper
+
+	// Timeout is the value of `--credential_helper_timeout`.
+	Timeout time.Duration
+
+	// CacheDuration is the value of `--credential_helper_cache_duration`.
+	CacheDuration time.Duration
+}
+
+// Load reads the rc files selected by options and returns the credential
+// helper configuration they specify.
+//
+// Options are interpreted like Bazel does: repeated `--credential_helper`
+// flags accumulate, later values of other flags override earlier ones, and
+// options of more specific commands (e.g., `test`) override those of the
+// commands they inherit from (e.g., `build`).
+func Load(options Options) (*Config, error) {
+	args, err := readRCFiles(options)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Workspace:     options.Workspace,
+		Timeout:       DefaultTimeout,
+		CacheDuration: DefaultCacheDuration,
+	}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--credential_helper", "--credential_helper_timeout", "--credential_helper_cache_duration":
+		default:
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for %s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "--credential_helper":
+			helper, err := parseHelper(value, options.Workspace)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Helpers = append(cfg.Helpers, helper)
+
+		case "--credential_helper_timeout":
+			if cfg.Timeout, err = parseDuration(value); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+
+		case "--credential_helper_cache_duration":
+			if cfg.CacheDuration, err = parseDuration(value); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// parseHelper parses the value of `--credential_helper`, which is either a
+// path or `<scope>=<path>`.
+func parseHelper(value string, workspace string) (Helper, error) {
+	var helper Helper
+	if scope, path, ok := strings.Cut(value, "="); ok {
+		if _, err := credentialhelperrouter.ParsePattern(scope); err != nil {
+			return Helper{}, fmt.Errorf("invalid value for --credential_helper: %w", err)
+		}
+		helper.Scope = scope
+		value = path
+	}
+	if value == "" {
+		return Helper{}, fmt.Errorf("invalid value for --credential_helper: missing path")
+	}
+
+	if strings.Contains(value, workspacePlaceholder) {
+		if workspace == "" {
+			return Helper{}, fmt.Errorf("credential helper %q references %s, but no workspace is set", value, workspacePlaceholder)
+		}
+		value = strings.ReplaceAll(value, workspacePlaceholder, workspace)
+	}
+	if !filepath.IsAbs(value) && strings.ContainsRune(value, filepath.Separator) && workspace != "" {
+		value = filepath.Join(workspace, value)
+	}
+	helper.Path = value
+	return helper, nil
+}
+
+// parseDuration parses a duration in any format accepted by Bazel or
+// [time.ParseDuration].
+func parseDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if m := bazelDurationPattern.FindStringSubmatch(value); m != nil {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		unit := map[string]time.Duration{
+			"d":  24 * time.Hour,
+			"h":  time.Hour,
+			"m":  time.Minute,
+			"s":  time.Second,
+			"ms": time.Millisecond,
+		}[m[2]]
+		duration = time.Duration(n) * unit
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(value); err != nil {
+			return 0, err
+		}
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("must not be negative, got %v", duration)
+	}
+	return duration, nil
+}
+
+// CredentialHelper represents the credential helpers of a [Config], combined
+// into a single [credentialhelper.CredentialHelper].
+//
+// Use [Config.NewCredentialHelper] to create an instance.
+type CredentialHelper interface {
+	credentialhelper.CredentialHelper
+
+	// Close releases all associated resources.
+	Close() error
+}
+
+// NewCredentialHelper returns a [CredentialHelper] invoking the configured
+// credential helpers for the hosts they are scoped to, and caching their
+// responses for [Config.CacheDuration] unless it is 0.
+//
+// Each credential helper is invoked with options, except that the timeout is
+// set to [Config.Timeout] and, unless set in options, the working directory
+// is the workspace, like Bazel does.
+func (cfg *Config) NewCredentialHelper(options credentialhelper.ClientOptions) (CredentialHelper, error) {
+	options.Timeout = cfg.Timeout
+	if options.WorkingDirectory == "" {
+		options.WorkingDirectory = cfg.Workspace
+	}
+
+	routes := make([]credentialhelperrouter.Route, 0, len(cfg.Helpers))
+	for _, helper := range cfg.Helpers {
+		client, err := credentialhelper.NewClientWithOptions(helper.Path, options)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, credentialhelperrouter.Route{
+			Pattern: helper.Scope,
+			Helper:  client,
+		})
+	}
+
+	router, err := credentialhelperrouter.New(routes)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CacheDuration == 0 {
+		// Bazel does not cache credentials in this case either.
+		return &uncachedCredentialHelper{router}, nil
+	}
+	return credentialhelpercache.New(router, credentialhelpercache.Options{TTL: cfg.CacheDuration})
+}
+
+// uncachedCredentialHelper adds a no-op Close to a credential helper.
+type uncachedCredentialHelper struct {
+	credentialhelper.CredentialHelper
+}
+
+func (h *uncachedCredentialHelper) Close() error {
+	return nil
+}
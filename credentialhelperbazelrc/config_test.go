@@ -0,0 +1,124 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelperbazelrc_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperbazelrc"
+)
+
+// writeHelper writes a credential helper to dir which returns name and its
+// working directory as headers.
+func writeHelper(t *testing.T, dir string, name string) {
+	t.Helper()
+
+	path := writeFile(t, dir, name, `#!/usr/bin/env bash
+cat > /dev/null
+echo "{\"headers\": {\"helper\": [\"$(basename "$0")\"], \"pwd\": [\"$PWD\"]}}"
+`)
+	if err := os.Chmod(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewCredentialHelper(t *testing.T) {
+	options := testOptions(t)
+	writeHelper(t, options.Workspace, "tools/default-helper")
+	writeHelper(t, options.Workspace, "tools/example-helper")
+	writeFile(t, options.Workspace, ".bazelrc", `
+build --credential_helper=%workspace%/tools/default-helper
+build --credential_helper=*.example.com=%workspace%/tools/example-helper
+`)
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper, err := cfg.NewCredentialHelper(credentialhelper.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer helper.Close()
+
+	// The workspace may be reached through a symlink (e.g., on macOS).
+	workspace, err := filepath.EvalSymlinks(options.Workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for uri, want := range map[string]string{
+		"https://foo.example.com": "example-helper",
+		"https://example.org":     "default-helper",
+	} {
+		response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantHeaders := map[string][]string{
+			"helper": {want},
+			"pwd":    {workspace},
+		}
+		if diff := cmp.Diff(wantHeaders, response.Headers); diff != "" {
+			t.Errorf("unexpected headers for %q (-want +got):\n%s", uri, diff)
+		}
+	}
+}
+
+func TestNewCredentialHelperWithoutDefault(t *testing.T) {
+	options := testOptions(t)
+	writeHelper(t, options.Workspace, "helper")
+	writeFile(t, options.Workspace, ".bazelrc", `
+build --credential_helper=example.com=%workspace%/helper
+build --credential_helper_cache_duration=0
+`)
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper, err := cfg.NewCredentialHelper(credentialhelper.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer helper.Close()
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.org"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestNewCredentialHelperMissingHelper(t *testing.T) {
+	options := testOptions(t)
+	writeFile(t, options.Workspace, ".bazelrc", "build --credential_helper=%workspace%/missing")
+
+	cfg, err := credentialhelperbazelrc.Load(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.NewCredentialHelper(credentialhelper.ClientOptions{}); err == nil {
+		t.Errorf("expected error for missing helper %q", filepath.Join(options.Workspace, "missing"))
+	}
+}
@@ -16,6 +16,7 @@ package credentialhelpercache
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
@@ -51,9 +52,9 @@ func New(delegate credentialhelper.CredentialHelper, options Options) (CachingCr
 		ttl = DefaultCacheDuration
 	}
 
-	cache := ttlcache.New[credentialhelper.GetCredentialsRequest, credentialhelper.GetCredentialsResponse](
-		ttlcache.WithTTL[credentialhelper.GetCredentialsRequest, credentialhelper.GetCredentialsResponse](ttl),
-		ttlcache.WithDisableTouchOnHit[credentialhelper.GetCredentialsRequest, credentialhelper.GetCredentialsResponse]())
+	cache := ttlcache.New[cacheKey, credentialhelper.GetCredentialsResponse](
+		ttlcache.WithTTL[cacheKey, credentialhelper.GetCredentialsResponse](ttl),
+		ttlcache.WithDisableTouchOnHit[cacheKey, credentialhelper.GetCredentialsResponse]())
 	go cache.Start()
 
 	c := &cachingCredentialHelper{
@@ -84,7 +85,19 @@ type cachingCredentialHelper struct {
 
 	cacheMutex sync.Mutex
 	closed     bool
-	cache      *ttlcache.Cache[credentialhelper.GetCredentialsRequest, credentialhelper.GetCredentialsResponse]
+	cache      *ttlcache.Cache[cacheKey, credentialhelper.GetCredentialsResponse]
+}
+
+// cacheKey identifies a request in the cache. Requests are not comparable, so
+// their canonical JSON encoding is used instead.
+type cacheKey string
+
+func newCacheKey(request *credentialhelper.GetCredentialsRequest) (cacheKey, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	return cacheKey(data), nil
 }
 
 func (c *cachingCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
@@ -95,7 +108,12 @@ func (c *cachingCredentialHelper) GetCredentials(ctx context.Context, request *c
 		return nil, errors.New("Cannot get credentials from closed Credential Helper")
 	}
 
-	if entry := c.cache.Get(*request); entry != nil && !entry.IsExpired() {
+	key, err := newCacheKey(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry := c.cache.Get(key); entry != nil && !entry.IsExpired() {
 		response := entry.Value()
 		return &response, nil
 	}
@@ -109,7 +127,7 @@ func (c *cachingCredentialHelper) GetCredentials(ctx context.Context, request *c
 	if response.Expires != nil {
 		ttl = response.Expires.Sub(time.Now())
 	}
-	c.cache.Set(*request, *response, ttl)
+	c.cache.Set(key, *response, ttl)
 
 	return response, nil
 }
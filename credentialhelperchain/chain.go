@@ -0,0 +1,209 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelperchain provides a [credentialhelper.CredentialHelper]
+// querying several credential helpers in order (e.g., an environment variable,
+// a `.netrc` file, and a corporate credential helper).
+package credentialhelperchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// Policy specifies how the responses of the helpers in a chain are combined.
+type Policy int
+
+const (
+	// PolicyFirst returns the response of the first helper in the chain that
+	// returns credentials, without querying the remaining helpers.
+	//
+	// This is the default.
+	PolicyFirst Policy = iota
+
+	// PolicyMerge queries all helpers in the chain and merges the headers of
+	// their responses. If several helpers return the same header, the values
+	// of the earliest helper win. The merged credentials expire when the
+	// first of them expires.
+	PolicyMerge
+)
+
+// Entry represents a credential helper in a chain.
+type Entry struct {
+	// Name identifies the helper in errors and reports (e.g., `netrc`).
+	Name string
+
+	// Helper is the credential helper.
+	Helper credentialhelper.CredentialHelper
+}
+
+// Attempt represents the outcome of querying a single helper of a chain.
+type Attempt struct {
+	// Name is the name of the helper.
+	Name string
+
+	// Err is the error returned by the helper, or nil if it returned
+	// credentials.
+	Err error
+}
+
+// Options represents options for a chain of credential helpers.
+type Options struct {
+	// Policy specifies how the responses of the helpers are combined.
+	Policy Policy
+
+	// TryNext reports whether an error returned by a helper means that the
+	// next helper should be queried. Any other error fails the request.
+	//
+	// If nil, only errors wrapping [credentialhelper.ErrNoCredentials] fall
+	// through to the next helper.
+	TryNext func(err error) bool
+
+	// Report, if set, is called after every request with the helpers that
+	// were queried, in order, for debugging which helper served a request.
+	Report func(ctx context.Context, request *credentialhelper.GetCredentialsRequest, attempts []Attempt)
+}
+
+// New returns a [credentialhelper.CredentialHelper] querying the helpers of
+// entries in order, combining their responses according to options.
+//
+// If no helper returns credentials, requests fail with an error wrapping
+// [credentialhelper.ErrNoCredentials] as well as the errors of all helpers.
+func New(entries []Entry, options Options) (credentialhelper.CredentialHelper, error) {
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Name == "" {
+			return nil, errors.New("credential helpers in a chain must have a name")
+		}
+		if entry.Helper == nil {
+			return nil, fmt.Errorf("credential helper %q has no helper", entry.Name)
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("credential helper %q is defined more than once", entry.Name)
+		}
+		names[entry.Name] = true
+	}
+
+	switch options.Policy {
+	case PolicyFirst, PolicyMerge:
+	default:
+		return nil, fmt.Errorf("unknown policy %d", options.Policy)
+	}
+	if options.TryNext == nil {
+		options.TryNext = func(err error) bool {
+			return errors.Is(err, credentialhelper.ErrNoCredentials)
+		}
+	}
+
+	c := &chain{
+		entries: append([]Entry{}, entries...),
+		options: options,
+	}
+	return c, nil
+}
+
+type chain struct {
+	credentialhelper.CredentialHelperBase
+
+	entries []Entry
+	options Options
+}
+
+// GetCredentials queries the helpers of the chain in order.
+func (c *chain) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	var attempts []Attempt
+	if c.options.Report != nil {
+		defer func() {
+			c.options.Report(ctx, request, attempts)
+		}()
+	}
+
+	var merged *credentialhelper.GetCredentialsResponse
+	for _, entry := range c.entries {
+		response, err := entry.Helper.GetCredentials(ctx, request, extraParameters...)
+		if err == nil && response == nil {
+			err = fmt.Errorf("%w: no response", credentialhelper.ErrNoCredentials)
+		}
+		attempts = append(attempts, Attempt{Name: entry.Name, Err: err})
+		if err != nil {
+			if c.options.TryNext(err) {
+				continue
+			}
+			return nil, fmt.Errorf("credential helper %q: %w", entry.Name, err)
+		}
+
+		if c.options.Policy == PolicyFirst {
+			return response, nil
+		}
+		merged = merge(merged, response)
+	}
+
+	if merged != nil {
+		return merged, nil
+	}
+	return nil, noCredentialsError(attempts)
+}
+
+// merge adds the headers of response to those of merged, unless merged
+// already has them, and returns the result.
+func merge(merged *credentialhelper.GetCredentialsResponse, response *credentialhelper.GetCredentialsResponse) *credentialhelper.GetCredentialsResponse {
+	if merged == nil {
+		merged = &credentialhelper.GetCredentialsResponse{
+			Headers: make(map[string][]string),
+		}
+	}
+
+	// Header names are case-insensitive.
+	present := make(map[string]bool)
+	for name := range merged.Headers {
+		present[strings.ToLower(name)] = true
+	}
+	for name, values := range response.Headers {
+		if !present[strings.ToLower(name)] {
+			merged.Headers[name] = values
+		}
+	}
+
+	for name, value := range response.Extensions {
+		if _, ok := merged.Extensions[name]; !ok {
+			if merged.Extensions == nil {
+				merged.Extensions = make(map[string]json.RawMessage)
+			}
+			merged.Extensions[name] = value
+		}
+	}
+
+	if response.Expires != nil && (merged.Expires == nil || response.Expires.Before(*merged.Expires)) {
+		merged.Expires = response.Expires
+	}
+	return merged
+}
+
+// noCredentialsError returns the error for a request none of the helpers
+// returned credentials for.
+func noCredentialsError(attempts []Attempt) error {
+	errs := make([]error, 0, len(attempts))
+	for _, attempt := range attempts {
+		errs = append(errs, fmt.Errorf("credential helper %q: %w", attempt.Name, attempt.Err))
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("%w: chain has no credential helpers", credentialhelper.ErrNoCredentials)
+	}
+	return fmt.Errorf("%w from any credential helper in the chain: %w", credentialhelper.ErrNoCredentials, errors.Join(errs...))
+}
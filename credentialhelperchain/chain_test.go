@@ -0,0 +1,231 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperchain_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperchain"
+)
+
+// staticCredentialHelper returns a fixed response or error.
+type staticCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	response *credentialhelper.GetCredentialsResponse
+	err      error
+	calls    int
+}
+
+func (h *staticCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	h.calls++
+	return h.response, h.err
+}
+
+func withHeaders(headers map[string][]string) *staticCredentialHelper {
+	return &staticCredentialHelper{
+		response: &credentialhelper.GetCredentialsResponse{Headers: headers},
+	}
+}
+
+func withError(err error) *staticCredentialHelper {
+	return &staticCredentialHelper{err: err}
+}
+
+var noCredentials = fmt.Errorf("%w: not configured", credentialhelper.ErrNoCredentials)
+
+func TestChainFirst(t *testing.T) {
+	last := withHeaders(map[string][]string{"Authorization": {"Bearer last"}})
+	var attempts []credentialhelperchain.Attempt
+	chain, err := credentialhelperchain.New([]credentialhelperchain.Entry{
+		{Name: "env", Helper: withError(noCredentials)},
+		{Name: "netrc", Helper: withHeaders(map[string][]string{"Authorization": {"Basic netrc"}})},
+		{Name: "last", Helper: last},
+	}, credentialhelperchain.Options{
+		Report: func(ctx context.Context, request *credentialhelper.GetCredentialsRequest, a []credentialhelperchain.Attempt) {
+			attempts = a
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(map[string][]string{"Authorization": {"Basic netrc"}}, response.Headers); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+	if last.calls != 0 {
+		t.Errorf("expected helpers after the first match not to be queried")
+	}
+
+	wantAttempts := []credentialhelperchain.Attempt{
+		{Name: "env", Err: noCredentials},
+		{Name: "netrc"},
+	}
+	if diff := cmp.Diff(wantAttempts, attempts, cmp.Comparer(func(a, b error) bool { return a == b })); diff != "" {
+		t.Errorf("unexpected attempts (-want +got):\n%s", diff)
+	}
+}
+
+func TestChainMerge(t *testing.T) {
+	earlier := time.Now().Add(time.Hour)
+	later := earlier.Add(time.Hour)
+
+	chain, err := credentialhelperchain.New([]credentialhelperchain.Entry{
+		{Name: "first", Helper: &staticCredentialHelper{response: &credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{"Authorization": {"Bearer first"}},
+			Expires: &later,
+		}}},
+		{Name: "missing", Helper: withError(noCredentials)},
+		{Name: "nil", Helper: &staticCredentialHelper{}},
+		{Name: "second", Helper: &staticCredentialHelper{response: &credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{"authorization": {"Bearer second"}, "X-Tenant": {"foo"}},
+			Expires: &earlier,
+		}}},
+	}, credentialhelperchain.Options{Policy: credentialhelperchain.PolicyMerge})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {"Bearer first"},
+			"X-Tenant":      {"foo"},
+		},
+		Expires: &earlier,
+	}
+	if diff := cmp.Diff(want, response); diff != "" {
+		t.Errorf("unexpected response (-want +got):\n%s", diff)
+	}
+}
+
+func TestChainNoCredentials(t *testing.T) {
+	for _, policy := range []credentialhelperchain.Policy{credentialhelperchain.PolicyFirst, credentialhelperchain.PolicyMerge} {
+		chain, err := credentialhelperchain.New([]credentialhelperchain.Entry{
+			{Name: "env", Helper: withError(noCredentials)},
+			{Name: "netrc", Helper: withError(noCredentials)},
+		}, credentialhelperchain.Options{Policy: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+		if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+			t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+		}
+	}
+
+	chain, err := credentialhelperchain.New(nil, credentialhelperchain.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials for empty chain, got %v", err)
+	}
+}
+
+func TestChainSkipsNilResponses(t *testing.T) {
+	for _, policy := range []credentialhelperchain.Policy{credentialhelperchain.PolicyFirst, credentialhelperchain.PolicyMerge} {
+		chain, err := credentialhelperchain.New([]credentialhelperchain.Entry{
+			{Name: "nil", Helper: &staticCredentialHelper{}},
+			{Name: "next", Helper: withHeaders(map[string][]string{"Authorization": {"Bearer next"}})},
+		}, credentialhelperchain.Options{Policy: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response, err := chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"Bearer next"}, response.Headers["Authorization"]); diff != "" {
+			t.Errorf("unexpected headers for policy %d (-want +got):\n%s", policy, diff)
+		}
+	}
+}
+
+func TestChainStopsAtOtherErrors(t *testing.T) {
+	failure := errors.New("helper crashed")
+	next := withHeaders(map[string][]string{"Authorization": {"Bearer next"}})
+
+	chain, err := credentialhelperchain.New([]credentialhelperchain.Entry{
+		{Name: "broken", Helper: withError(failure)},
+		{Name: "next", Helper: next},
+	}, credentialhelperchain.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if !errors.Is(err, failure) {
+		t.Errorf("expected error wrapping %v, got %v", failure, err)
+	}
+	if next.calls != 0 {
+		t.Errorf("expected chain to stop at the first unexpected error")
+	}
+}
+
+func TestChainCustomTryNext(t *testing.T) {
+	chain, err := credentialhelperchain.New([]credentialhelperchain.Entry{
+		{Name: "transient", Helper: withError(credentialhelper.ErrTransient)},
+		{Name: "next", Helper: withHeaders(map[string][]string{"Authorization": {"Bearer next"}})},
+	}, credentialhelperchain.Options{
+		TryNext: func(err error) bool {
+			return errors.Is(err, credentialhelper.ErrNoCredentials) || errors.Is(err, credentialhelper.ErrTransient)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := chain.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Bearer next"}, response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewRejectsInvalidEntries(t *testing.T) {
+	helper := withHeaders(nil)
+	for _, entries := range [][]credentialhelperchain.Entry{
+		{{Helper: helper}},
+		{{Name: "missing"}},
+		{{Name: "twice", Helper: helper}, {Name: "twice", Helper: helper}},
+	} {
+		if _, err := credentialhelperchain.New(entries, credentialhelperchain.Options{}); err == nil {
+			t.Errorf("expected error for entries %v", entries)
+		}
+	}
+}
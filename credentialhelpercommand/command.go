@@ -0,0 +1,231 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelpercommand provides a [credentialhelper.CredentialHelper]
+// running an arbitrary command printing a token (e.g.,
+// `gcloud auth print-access-token`) and turning its output into headers.
+package credentialhelpercommand
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// Format specifies how the output of the command is interpreted.
+type Format int
+
+const (
+	// FormatText uses the output of the command, without leading and
+	// trailing whitespace, as token.
+	//
+	// This is the default.
+	FormatText Format = iota
+
+	// FormatJSON parses the output of the command as JSON and uses the string
+	// at [Options.TokenPath] as token.
+	FormatJSON
+
+	// FormatCredentialProcess parses the output of the command in the format
+	// of AWS `credential_process`, using the session token as token.
+	// `AccessKeyId`, `SecretAccessKey` and `SessionToken` are available to
+	// header templates, and `Expiration` is used as expiry. Long-term keys
+	// have no session token, so rendering a header referring to it (such as
+	// those in [DefaultHeaders]) fails.
+	FormatCredentialProcess
+)
+
+// DefaultHeaders are the headers returned if none are configured.
+var DefaultHeaders = map[string]string{
+	"Authorization": "Bearer {{.token}}",
+}
+
+// Options represents options for running a command printing a token.
+type Options struct {
+	// Command is the command to run, followed by its arguments.
+	Command []string
+
+	// Format specifies how the output of the command is interpreted.
+	Format Format
+
+	// TokenPath is the path of the token in the output for [FormatJSON], as
+	// field names and array indices separated by dots (e.g.,
+	// `credentials.0.token`). If empty, the output must be a JSON string.
+	TokenPath string
+
+	// ExpiryPath is the path of the expiry in the output for [FormatJSON],
+	// either an RFC 3339 timestamp or Unix epoch seconds. It is optional.
+	ExpiryPath string
+
+	// Headers maps the names of the headers to return to [text/template]
+	// templates of their values, which can refer to the token as
+	// `{{.token}}` and encode values with `{{base64 ...}}` (e.g.,
+	// `Basic {{base64 "user:" .token}}`).
+	//
+	// If empty, it defaults to [DefaultHeaders].
+	Headers map[string]string
+
+	// TTL specifies how long the token is valid, if the output of the
+	// command does not specify when it expires. If not set, the response
+	// has no expiry.
+	TTL time.Duration
+
+	// Program specifies how the command is invoked.
+	Program credentialhelper.ClientOptions
+}
+
+// New returns a [credentialhelper.CredentialHelper] running the command
+// configured in options for every request, and returning the configured
+// headers with the token extracted from its output.
+func New(options Options) (credentialhelper.CredentialHelper, error) {
+	if len(options.Command) == 0 {
+		return nil, errors.New("missing command")
+	}
+	switch options.Format {
+	case FormatText, FormatJSON, FormatCredentialProcess:
+	default:
+		return nil, fmt.Errorf("unknown format %d", options.Format)
+	}
+	if options.TTL < 0 {
+		return nil, fmt.Errorf("ttl must not be negative, got %v", options.TTL)
+	}
+
+	headers := options.Headers
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	templates := make(map[string]*template.Template, len(headers))
+	for name, value := range headers {
+		t, err := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for header %q: %w", name, err)
+		}
+		templates[name] = t
+	}
+
+	programOptions := options.Program
+	programOptions.Args = append(append([]string{}, programOptions.Args...), options.Command[1:]...)
+	program, err := credentialhelper.NewProgram(options.Command[0], programOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &commandCredentialHelper{
+		program:   program,
+		options:   options,
+		templates: templates,
+	}
+	return c, nil
+}
+
+// templateFuncs are the functions available to header templates.
+var templateFuncs = template.FuncMap{
+	"base64": func(values ...string) string {
+		return base64.StdEncoding.EncodeToString([]byte(strings.Join(values, "")))
+	},
+}
+
+type commandCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	program   *credentialhelper.Program
+	options   Options
+	templates map[string]*template.Template
+}
+
+// GetCredentials runs the command and returns the headers for its output.
+func (c *commandCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	start := time.Now()
+	stdout, err := c.program.Run(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values, expires, err := c.parseOutput(stdout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output of command %q: %w", c.program.Path(), err)
+	}
+	if values["token"] == "" && c.options.Format != FormatCredentialProcess {
+		return nil, fmt.Errorf("command %q returned an empty token", c.program.Path())
+	}
+	if expires == nil && c.options.TTL > 0 {
+		// Measure from the start, as the token might have been issued at any
+		// point while the command ran.
+		t := start.Add(c.options.TTL)
+		expires = &t
+	}
+
+	headers := make(map[string][]string, len(c.templates))
+	for name, t := range c.templates {
+		var value bytes.Buffer
+		if err := t.Execute(&value, values); err != nil {
+			return nil, fmt.Errorf("could not render header %q: %w", name, err)
+		}
+		headers[name] = []string{value.String()}
+	}
+
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: headers,
+		Expires: expires,
+	}, nil
+}
+
+// parseOutput extracts the values available to templates, and the expiry if
+// any, from the output of the command.
+func (c *commandCredentialHelper) parseOutput(stdout []byte) (map[string]string, *time.Time, error) {
+	switch c.options.Format {
+	case FormatJSON:
+		var document any
+		decoder := json.NewDecoder(bytes.NewReader(stdout))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, nil, err
+		}
+
+		token, err := lookupPath(document, c.options.TokenPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		tokenString, ok := token.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("token at %q is not a string", c.options.TokenPath)
+		}
+
+		var expires *time.Time
+		if c.options.ExpiryPath != "" {
+			expiry, err := lookupPath(document, c.options.ExpiryPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			if expires, err = parseExpiry(expiry); err != nil {
+				return nil, nil, err
+			}
+		}
+		return map[string]string{"token": tokenString}, expires, nil
+
+	case FormatCredentialProcess:
+		return parseCredentialProcess(stdout)
+
+	default:
+		return map[string]string{"token": strings.TrimSpace(string(stdout))}, nil, nil
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelpercommand_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelpercommand"
+)
+
+// printf returns a command printing output.
+func printf(output string) []string {
+	return []string{"printf", "%s", output}
+}
+
+func TestText(t *testing.T) {
+	start := time.Now()
+	helper, err := credentialhelpercommand.New(credentialhelpercommand.Options{
+		Command: printf("  token\n"),
+		TTL:     time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(map[string][]string{"Authorization": {"Bearer token"}}, response.Headers); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+	if response.Expires == nil || response.Expires.Before(start.Add(time.Hour)) || response.Expires.After(time.Now().Add(time.Hour)) {
+		t.Errorf("expected expiry after TTL, got %v", response.Expires)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	helper, err := credentialhelpercommand.New(credentialhelpercommand.Options{
+		Command:    printf(`{"credentials": [{"token": "secret", "expiresAt": 981173106}]}`),
+		Format:     credentialhelpercommand.FormatJSON,
+		TokenPath:  "credentials.0.token",
+		ExpiryPath: "credentials.0.expiresAt",
+		Headers: map[string]string{
+			"Authorization": `Basic {{base64 "aws:" .token}}`,
+			"X-Token":       "{{.token}}",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Unix(981173106, 0)
+	want := &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte("aws:secret"))},
+			"X-Token":       {"secret"},
+		},
+		Expires: &expires,
+	}
+	if diff := cmp.Diff(want, response); diff != "" {
+		t.Errorf("unexpected response (-want +got):\n%s", diff)
+	}
+}
+
+func TestJSONString(t *testing.T) {
+	// E.g., `aws codeartifact get-authorization-token --query authorizationToken`.
+	helper, err := credentialhelpercommand.New(credentialhelpercommand.Options{
+		Command: printf(`"token"`),
+		Format:  credentialhelpercommand.FormatJSON,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Bearer token"}, response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+}
+
+func TestCredentialProcess(t *testing.T) {
+	helper, err := credentialhelpercommand.New(credentialhelpercommand.Options{
+		Command: printf(`{"Version": 1, "AccessKeyId": "AKID", "SecretAccessKey": "secret", "SessionToken": "session", "Expiration": "2030-01-02T03:04:05Z"}`),
+		Format:  credentialhelpercommand.FormatCredentialProcess,
+		Headers: map[string]string{
+			"X-Access-Key":    "{{.AccessKeyId}}",
+			"X-Session-Token": "{{.token}}",
+		},
+		TTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"X-Access-Key":    {"AKID"},
+			"X-Session-Token": {"session"},
+		},
+		Expires: &expires,
+	}
+	if diff := cmp.Diff(want, response); diff != "" {
+		t.Errorf("unexpected response (-want +got):\n%s", diff)
+	}
+}
+
+func TestCredentialProcessLongTermKeys(t *testing.T) {
+	helper, err := credentialhelpercommand.New(credentialhelpercommand.Options{
+		Command: printf(`{"Version": 1, "AccessKeyId": "AKID", "SecretAccessKey": "secret"}`),
+		Format:  credentialhelpercommand.FormatCredentialProcess,
+		Headers: map[string]string{
+			"Authorization": `Basic {{base64 .AccessKeyId ":" .SecretAccessKey}}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte("AKID:secret"))}}
+	if diff := cmp.Diff(want, response.Headers); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutputErrors(t *testing.T) {
+	for _, tc := range []struct {
+		options credentialhelpercommand.Options
+		want    string
+	}{
+		{
+			options: credentialhelpercommand.Options{Command: printf("  \n")},
+			want:    "empty token",
+		},
+		{
+			options: credentialhelpercommand.Options{Command: printf("{"), Format: credentialhelpercommand.FormatJSON},
+			want:    "invalid output",
+		},
+		{
+			options: credentialhelpercommand.Options{Command: printf(`{"a": [1]}`), Format: credentialhelpercommand.FormatJSON, TokenPath: "a.1"},
+			want:    `"a.1": invalid index`,
+		},
+		{
+			options: credentialhelpercommand.Options{Command: printf(`{"a": 1}`), Format: credentialhelpercommand.FormatJSON, TokenPath: "a"},
+			want:    "not a string",
+		},
+		{
+			options: credentialhelpercommand.Options{Command: printf(`{"a": "t", "e": "soon"}`), Format: credentialhelpercommand.FormatJSON, TokenPath: "a", ExpiryPath: "e"},
+			want:    "invalid expiry",
+		},
+		{
+			options: credentialhelpercommand.Options{Command: printf(`{"Version": 2, "AccessKeyId": "AKID"}`), Format: credentialhelpercommand.FormatCredentialProcess},
+			want:    "unsupported credential_process version",
+		},
+		{
+			// Long-term keys have no session token for the default headers.
+			options: credentialhelpercommand.Options{Command: printf(`{"Version": 1, "AccessKeyId": "AKID", "SecretAccessKey": "secret"}`), Format: credentialhelpercommand.FormatCredentialProcess},
+			want:    `could not render header "Authorization"`,
+		},
+		{
+			options: credentialhelpercommand.Options{Command: printf("token"), Headers: map[string]string{"Authorization": "{{.missing}}"}},
+			want:    "could not render header",
+		},
+		{
+			options: credentialhelpercommand.Options{Command: []string{"false"}},
+			want:    "exit status 1",
+		},
+	} {
+		helper, err := credentialhelpercommand.New(tc.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("expected error containing %q for %v, got %v", tc.want, tc.options.Command, err)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, options := range []credentialhelpercommand.Options{
+		{},
+		{Command: []string{"testdata/missing.sh"}},
+		{Command: printf("token"), Format: 42},
+		{Command: printf("token"), TTL: -time.Second},
+		{Command: printf("token"), Headers: map[string]string{"Authorization": "{{"}},
+	} {
+		if _, err := credentialhelpercommand.New(options); err == nil {
+			t.Errorf("expected error for %+v", options)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelpercommand
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupPath returns the value at path in document, which was decoded with
+// [json.Decoder.UseNumber].
+func lookupPath(document any, path string) (any, error) {
+	if path == "" {
+		return document, nil
+	}
+
+	segments := strings.Split(path, ".")
+	value := document
+	for i, segment := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			field, ok := v[segment]
+			if !ok {
+				return nil, fmt.Errorf("%q: no field %q", strings.Join(segments[:i+1], "."), segment)
+			}
+			value = field
+
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("%q: invalid index %q for array of length %d", strings.Join(segments[:i+1], "."), segment, len(v))
+			}
+			value = v[index]
+
+		default:
+			return nil, fmt.Errorf("%q: cannot look up %q in %T", path, segment, value)
+		}
+	}
+	return value, nil
+}
+
+// parseExpiry parses an expiry given as RFC 3339 timestamp or Unix epoch
+// seconds.
+func parseExpiry(value any) (*time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		expires, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry %q: %w", v, err)
+		}
+		return &expires, nil
+
+	case json.Number:
+		seconds, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry %s: %w", v, err)
+		}
+		expires := time.Unix(seconds, 0)
+		return &expires, nil
+
+	default:
+		return nil, fmt.Errorf("invalid expiry of type %T", value)
+	}
+}
+
+// credentialProcessOutput represents the output of an AWS
+// `credential_process`.
+type credentialProcessOutput struct {
+	Version         int
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      string
+}
+
+// parseCredentialProcess parses the output of an AWS `credential_process`.
+func parseCredentialProcess(stdout []byte) (map[string]string, *time.Time, error) {
+	var output credentialProcessOutput
+	if err := json.Unmarshal(stdout, &output); err != nil {
+		return nil, nil, err
+	}
+	if output.Version != 1 {
+		return nil, nil, fmt.Errorf("unsupported credential_process version %d", output.Version)
+	}
+	if output.AccessKeyId == "" {
+		return nil, nil, fmt.Errorf("missing AccessKeyId")
+	}
+
+	var expires *time.Time
+	if output.Expiration != "" {
+		var err error
+		if expires, err = parseExpiry(output.Expiration); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	values := map[string]string{
+		"AccessKeyId":     output.AccessKeyId,
+		"SecretAccessKey": output.SecretAccessKey,
+	}
+	// Long-term keys come without a session token. Leaving it out makes
+	// templates referring to it fail instead of rendering an empty value.
+	if output.SessionToken != "" {
+		values["token"] = output.SessionToken
+		values["SessionToken"] = output.SessionToken
+	}
+	return values, expires, nil
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelperdocker provides a [credentialhelper.CredentialHelper]
+// returning the credentials Docker uses for container registries, as
+// configured in `~/.docker/config.json`.
+package credentialhelperdocker
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+const (
+	// ConfigEnvironmentVariable specifies the directory containing the Docker
+	// configuration, overriding `~/.docker`.
+	ConfigEnvironmentVariable = "DOCKER_CONFIG"
+
+	// ProgramPrefix is the prefix of the names of Docker credential helpers
+	// (e.g., `docker-credential-gcloud`).
+	ProgramPrefix = "docker-credential-"
+
+	// dockerHubServerURL is the server URL Docker uses for Docker Hub.
+	dockerHubServerURL = "https://index.docker.io/v1/"
+
+	// tokenUsername is the username Docker credential helpers return for
+	// identity tokens.
+	tokenUsername = "<token>"
+
+	// notFoundMessage is written to stdout by Docker credential helpers which
+	// have no credentials for a server.
+	notFoundMessage = "credentials not found in native keychain"
+)
+
+// dockerHubHosts are the hosts of Docker Hub, which all use the credentials
+// stored for [dockerHubServerURL].
+var dockerHubHosts = map[string]bool{
+	"docker.io":            true,
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+// AuthConfig represents an entry of the `auths` section of the Docker
+// configuration.
+type AuthConfig struct {
+	// Auth holds `<username>:<password>`, encoded as base64.
+	Auth string `json:"auth,omitempty"`
+
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// IdentityToken is an OAuth refresh token, which is not supported.
+	IdentityToken string `json:"identitytoken,omitempty"`
+
+	// RegistryToken is a bearer token for the registry.
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// Config represents the parts of the Docker configuration relevant for
+// credentials.
+type Config struct {
+	// Auths holds credentials keyed by registry.
+	Auths map[string]AuthConfig `json:"auths,omitempty"`
+
+	// CredsStore is the suffix of the Docker credential helper storing the
+	// credentials for all registries without entry in CredHelpers.
+	CredsStore string `json:"credsStore,omitempty"`
+
+	// CredHelpers holds the suffix of the Docker credential helper to use
+	// keyed by registry.
+	CredHelpers map[string]string `json:"credHelpers,omitempty"`
+}
+
+// DefaultConfigPath returns the path of the Docker configuration of the
+// current user: `config.json` in the directory specified by
+// [ConfigEnvironmentVariable], or in `~/.docker` if it is not set.
+func DefaultConfigPath() (string, error) {
+	dir := os.Getenv(ConfigEnvironmentVariable)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// LoadConfig parses the Docker configuration at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// Options represents options for the Docker credential helper adapter.
+type Options struct {
+	// Program specifies how Docker credential helpers are invoked.
+	Program credentialhelper.ClientOptions
+}
+
+// New returns a [credentialhelper.CredentialHelper] returning the credentials
+// configured in config for the registry at the host of the requested URI.
+//
+// It asks the Docker credential helper configured for the registry in
+// CredHelpers, or else the one configured in CredsStore. If that has no
+// credentials, or no Docker credential helper is configured, it uses the
+// entry of Auths for the registry. Only credentials which can be
+// sent as `Basic` or `Bearer` `Authorization` header are supported.
+//
+// Requests for registries without credentials fail with an error wrapping
+// [credentialhelper.ErrNoCredentials].
+func New(config *Config, options Options) credentialhelper.CredentialHelper {
+	h := &dockerCredentialHelper{
+		auths:       make(map[string]AuthConfig),
+		credsStore:  config.CredsStore,
+		credHelpers: make(map[string]string),
+		options:     options,
+	}
+	for server, auth := range config.Auths {
+		h.auths[registryHost(server)] = auth
+	}
+	for server, helper := range config.CredHelpers {
+		h.credHelpers[registryHost(server)] = helper
+	}
+	return h
+}
+
+type dockerCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	auths       map[string]AuthConfig
+	credsStore  string
+	credHelpers map[string]string
+	options     Options
+}
+
+// GetCredentials looks up credentials for the registry of the requested URI.
+func (h *dockerCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	u, err := url.Parse(request.URI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI %q: %w", request.URI, err)
+	}
+	registry := registryHost(u.Host)
+	if registry == "" {
+		return nil, fmt.Errorf("URI %q has no host", request.URI)
+	}
+	serverURL := registry
+	if dockerHubHosts[registry] {
+		registry = registryHost(dockerHubServerURL)
+		serverURL = dockerHubServerURL
+	}
+
+	helper, ok := h.credHelpers[registry]
+	if !ok {
+		helper = h.credsStore
+	}
+	if helper != "" {
+		username, secret, err := GetFromProgram(ctx, helper, serverURL, h.options.Program)
+		if err == nil {
+			return basicResponse(username, secret), nil
+		} else if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+			return nil, err
+		}
+	}
+
+	auth, ok := h.auths[registry]
+	if !ok {
+		return nil, fmt.Errorf("%w: no Docker credentials for registry %q", credentialhelper.ErrNoCredentials, registry)
+	}
+	return authResponse(registry, auth)
+}
+
+// registryHost returns the host of a registry as used by Docker to look up
+// credentials (e.g., `registry.example.com:5000` for
+// `https://registry.example.com:5000/v2/`).
+func registryHost(server string) string {
+	server = strings.TrimPrefix(server, "http://")
+	server = strings.TrimPrefix(server, "https://")
+	host, _, _ := strings.Cut(server, "/")
+	return strings.ToLower(host)
+}
+
+// authResponse returns the response for an entry of the `auths` section.
+func authResponse(registry string, auth AuthConfig) (*credentialhelper.GetCredentialsResponse, error) {
+	switch {
+	case auth.Auth != "":
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Docker credentials for registry %q: %w", registry, err)
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid Docker credentials for registry %q: auth is not of the form <username>:<password>", registry)
+		}
+		return basicResponse(username, password), nil
+
+	case auth.Username != "" || auth.Password != "":
+		return basicResponse(auth.Username, auth.Password), nil
+
+	case auth.RegistryToken != "":
+		return &credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{
+				"Authorization": {"Bearer " + auth.RegistryToken},
+			},
+		}, nil
+
+	case auth.IdentityToken != "":
+		return nil, fmt.Errorf("Docker credentials for registry %q are an identity token, which is not supported", registry)
+
+	default:
+		return nil, fmt.Errorf("%w: no Docker credentials for registry %q", credentialhelper.ErrNoCredentials, registry)
+	}
+}
+
+func basicResponse(username string, password string) *credentialhelper.GetCredentialsResponse {
+	token := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {"Basic " + token},
+		},
+	}
+}
+
+// programResponse represents the response of the `get` command of a Docker
+// credential helper.
+type programResponse struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// GetFromProgram invokes the `get` command of the Docker credential helper
+// `docker-credential-<helper>` for serverURL, and returns the username and
+// secret it returns.
+//
+// If the Docker credential helper has no credentials for serverURL, the error
+// wraps [credentialhelper.ErrNoCredentials]. Identity tokens are not
+// supported.
+func GetFromProgram(ctx context.Context, helper string, serverURL string, options credentialhelper.ClientOptions) (username string, secret string, err error) {
+	program, err := credentialhelper.NewProgram(ProgramPrefix+helper, options)
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout, err := program.Run(ctx, []byte(serverURL), "get")
+	if err != nil {
+		var helperErr *credentialhelper.HelperError
+		if errors.As(err, &helperErr) && helperErr.Phase == credentialhelper.PhaseExit && bytes.Contains(stdout, []byte(notFoundMessage)) {
+			return "", "", fmt.Errorf("%w: %s%s has no credentials for %q", credentialhelper.ErrNoCredentials, ProgramPrefix, helper, serverURL)
+		}
+		return "", "", err
+	}
+
+	var response programResponse
+	if err := json.Unmarshal(stdout, &response); err != nil {
+		return "", "", fmt.Errorf("invalid response from %s%s: %w", ProgramPrefix, helper, err)
+	}
+	if response.Username == tokenUsername {
+		return "", "", fmt.Errorf("%s%s returned an identity token for %q, which is not supported", ProgramPrefix, helper, serverURL)
+	}
+	return response.Username, response.Secret, nil
+}
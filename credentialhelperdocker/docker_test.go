@@ -0,0 +1,206 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelperdocker_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperdocker"
+)
+
+// useFakePrograms puts the fake Docker credential helpers in testdata on the
+// PATH.
+func useFakePrograms(t *testing.T) {
+	testdata, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", testdata+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func basic(username string, password string) map[string][]string {
+	token := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return map[string][]string{"Authorization": {"Basic " + token}}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(credentialhelperdocker.ConfigEnvironmentVariable, dir)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{
+		"auths": {"https://registry.example.com/v1/": {"auth": "dXNlcjpwYXNz"}},
+		"credsStore": "desktop",
+		"credHelpers": {"gcr.io": "gcloud"},
+		"experimental": "enabled"
+	}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := credentialhelperdocker.DefaultConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := credentialhelperdocker.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &credentialhelperdocker.Config{
+		Auths: map[string]credentialhelperdocker.AuthConfig{
+			"https://registry.example.com/v1/": {Auth: "dXNlcjpwYXNz"},
+		},
+		CredsStore:  "desktop",
+		CredHelpers: map[string]string{"gcr.io": "gcloud"},
+	}
+	if diff := cmp.Diff(want, config); diff != "" {
+		t.Errorf("unexpected config (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuths(t *testing.T) {
+	config := &credentialhelperdocker.Config{
+		Auths: map[string]credentialhelperdocker.AuthConfig{
+			"https://registry.example.com/v1/": {Auth: base64.StdEncoding.EncodeToString([]byte("user:pa:ss"))},
+			"localhost:5000":                   {Username: "local", Password: "secret"},
+			"token.example.com":                {RegistryToken: "registry-token"},
+			"https://index.docker.io/v1/":      {Auth: base64.StdEncoding.EncodeToString([]byte("hub:secret"))},
+		},
+	}
+	helper := credentialhelperdocker.New(config, credentialhelperdocker.Options{})
+
+	for uri, want := range map[string]map[string][]string{
+		"https://registry.example.com/v2/foo/manifests/latest": basic("user", "pa:ss"),
+		"http://localhost:5000/v2/":                            basic("local", "secret"),
+		"https://token.example.com/v2/":                        {"Authorization": {"Bearer registry-token"}},
+		"https://registry-1.docker.io/v2/library/ubuntu":       basic("hub", "secret"),
+	} {
+		response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, response.Headers); diff != "" {
+			t.Errorf("unexpected headers for %q (-want +got):\n%s", uri, diff)
+		}
+	}
+}
+
+func TestAuthsErrors(t *testing.T) {
+	config := &credentialhelperdocker.Config{
+		Auths: map[string]credentialhelperdocker.AuthConfig{
+			"identity.example.com": {IdentityToken: "refresh"},
+			"invalid.example.com":  {Auth: "not base64"},
+			"empty.example.com":    {},
+		},
+	}
+	helper := credentialhelperdocker.New(config, credentialhelperdocker.Options{})
+
+	_, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://identity.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "identity token") {
+		t.Errorf("expected error for identity token, got %v", err)
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://invalid.example.com"})
+	if err == nil {
+		t.Error("expected error for invalid auth")
+	}
+
+	for _, uri := range []string{"https://empty.example.com", "https://unknown.example.com"} {
+		_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+			t.Errorf("expected error wrapping ErrNoCredentials for %q, got %v", uri, err)
+		}
+	}
+}
+
+func TestCredHelpers(t *testing.T) {
+	useFakePrograms(t)
+	config := &credentialhelperdocker.Config{
+		Auths: map[string]credentialhelperdocker.AuthConfig{
+			"other.example.com": {Username: "from", Password: "auths"},
+		},
+		CredHelpers: map[string]string{
+			"registry.example.com": "fake",
+			"other.example.com":    "fake",
+			"broken.example.com":   "broken",
+			"token.example.com":    "fake",
+			"missing.example.com":  "missing",
+		},
+	}
+	helper := credentialhelperdocker.New(config, credentialhelperdocker.Options{})
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://registry.example.com/v2/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(basic("fake", "secret for registry.example.com"), response.Headers); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+
+	// The helper has no credentials, so those in auths are used.
+	response, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://other.example.com/v2/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(basic("from", "auths"), response.Headers); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+
+	var helperErr *credentialhelper.HelperError
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://broken.example.com"})
+	if !errors.As(err, &helperErr) || helperErr.ExitCode != 2 || !strings.Contains(string(helperErr.Stderr), "keychain is locked") {
+		t.Errorf("expected error of broken helper, got %v", err)
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://token.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "identity token") {
+		t.Errorf("expected error for identity token, got %v", err)
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://missing.example.com"})
+	if !errors.As(err, &helperErr) || helperErr.Phase != credentialhelper.PhaseLookup {
+		t.Errorf("expected lookup error for missing helper, got %v", err)
+	}
+}
+
+func TestCredsStore(t *testing.T) {
+	useFakePrograms(t)
+	config := &credentialhelperdocker.Config{
+		CredsStore: "fake",
+	}
+	helper := credentialhelperdocker.New(config, credentialhelperdocker.Options{})
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://docker.io/v2/library/ubuntu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(basic("fake", "secret for https://index.docker.io/v1/"), response.Headers); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://unknown.example.com"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperdocker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// StartCredentialHelper turns the current process into a Docker credential
+// helper (e.g., `docker-credential-example`) serving credentials from
+// helper.
+//
+// The `get` command reads a server URL from stdin and asks helper for
+// credentials for it, prefixed with `https://` unless it has a scheme. Only
+// responses with a single `Basic` `Authorization` header can be
+// represented in the Docker protocol; other responses fail. The `list`
+// command prints no credentials, and `store` and `erase` are not
+// supported.
+//
+// The context passed to the helper is canceled when the process receives
+// SIGINT or SIGTERM. A deadline can be set with the `--timeout=<duration>`
+// option before the command or with
+// [credentialhelper.TimeoutEnvironmentVariable].
+//
+// This function never returns.
+func StartCredentialHelper(helper credentialhelper.CredentialHelper) {
+	timeout, args, err := credentialhelper.ParseTimeout(os.Args)
+	if err != nil {
+		// Following the Docker protocol, errors are written to stdout.
+		fmt.Fprintln(os.Stdout, err.Error())
+		os.Exit(1)
+	}
+	credentialhelper.RunMain(timeout, func(ctx context.Context) int {
+		return startCredentialHelper(ctx, os.Stdin, os.Stdout, args, helper)
+	})
+}
+
+// startCredentialHelper serves a single Docker credential helper command.
+// Following the Docker protocol, errors are written to stdout.
+func startCredentialHelper(ctx context.Context, stdin io.Reader, stdout io.Writer, args []string, helper credentialhelper.CredentialHelper) int {
+	if len(args) != 2 {
+		fmt.Fprintln(stdout, "Usage: "+path.Base(args[0])+" <get|list|store|erase>")
+		return 1
+	}
+
+	switch args[1] {
+	case "get":
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stdout, err.Error())
+			return 1
+		}
+
+		response, err := getForServerURL(ctx, helper, strings.TrimSpace(string(input)))
+		if err != nil {
+			fmt.Fprintln(stdout, err.Error())
+			return 1
+		}
+		if err := json.NewEncoder(stdout).Encode(response); err != nil {
+			fmt.Fprintln(stdout, err.Error())
+			return 1
+		}
+		return 0
+
+	case "list":
+		// Credentials are only known once requested.
+		fmt.Fprintln(stdout, "{}")
+		return 0
+
+	case "store", "erase":
+		io.Copy(io.Discard, stdin)
+		fmt.Fprintln(stdout, "Command '"+args[1]+"' is not supported: credentials are managed by the credential helper")
+		return 1
+
+	default:
+		fmt.Fprintln(stdout, "Unknown command '"+args[1]+"'")
+		return 1
+	}
+}
+
+// getForServerURL returns the response of the Docker credential helper
+// protocol for serverURL.
+func getForServerURL(ctx context.Context, helper credentialhelper.CredentialHelper, serverURL string) (*programResponse, error) {
+	if serverURL == "" {
+		return nil, errors.New("missing server URL")
+	}
+
+	uri := serverURL
+	if !strings.Contains(uri, "://") {
+		uri = "https://" + uri
+	}
+
+	response, err := helper.GetCredentials(ctx, &credentialhelper.GetCredentialsRequest{URI: uri})
+	if errors.Is(err, credentialhelper.ErrNoCredentials) {
+		// Docker recognizes missing credentials by this exact message.
+		return nil, errors.New(notFoundMessage)
+	} else if err != nil {
+		return nil, err
+	}
+
+	username, secret, err := basicCredentials(response)
+	if err != nil {
+		return nil, fmt.Errorf("credentials for %q cannot be used by Docker: %w", serverURL, err)
+	}
+	return &programResponse{
+		ServerURL: serverURL,
+		Username:  username,
+		Secret:    secret,
+	}, nil
+}
+
+// basicCredentials extracts the username and password of a response
+// consisting of a single `Basic` `Authorization` header.
+func basicCredentials(response *credentialhelper.GetCredentialsResponse) (username string, password string, err error) {
+	credentials, err := response.AuthorizationCredentials("Basic")
+	if err != nil {
+		return "", "", err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid Basic credentials: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", errors.New("invalid Basic credentials: missing colon")
+	}
+	return username, password, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperdocker
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperenv"
+)
+
+// runDockerHelper runs the Docker credential helper command with stdin and
+// returns its exit code and output.
+func runDockerHelper(t *testing.T, helper credentialhelper.CredentialHelper, stdin string, args ...string) (int, string) {
+	t.Helper()
+
+	var stdout bytes.Buffer
+	exitCode := startCredentialHelper(context.Background(), strings.NewReader(stdin), &stdout, append([]string{"/bin/docker-credential-test"}, args...), helper)
+	return exitCode, stdout.String()
+}
+
+func TestServerGet(t *testing.T) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pa:ss"))
+	helper, err := credentialhelperenv.New([]string{
+		"CREDENTIAL_HELPER_HEADER_registry.example.com_Authorization=" + basic,
+		"CREDENTIAL_HELPER_HEADER_index.docker.io_Authorization=" + basic,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, serverURL := range []string{"registry.example.com", "https://index.docker.io/v1/"} {
+		exitCode, stdout := runDockerHelper(t, helper, serverURL+"\n", "get")
+		if exitCode != 0 {
+			t.Fatalf("unexpected exit code %d: %s", exitCode, stdout)
+		}
+		want := `{"ServerURL":"` + serverURL + `","Username":"user","Secret":"pa:ss"}` + "\n"
+		if diff := cmp.Diff(want, stdout); diff != "" {
+			t.Errorf("unexpected output (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestServerGetNotFound(t *testing.T) {
+	helper, err := credentialhelperenv.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exitCode, stdout := runDockerHelper(t, helper, "registry.example.com", "get")
+	if exitCode != 1 || stdout != notFoundMessage+"\n" {
+		t.Errorf("expected not found error, got exit code %d and %q", exitCode, stdout)
+	}
+}
+
+func TestServerGetUnsupportedCredentials(t *testing.T) {
+	for _, environ := range [][]string{
+		{"CREDENTIAL_HELPER_HEADER_registry.example.com_Authorization=Bearer token"},
+		{"CREDENTIAL_HELPER_HEADER_registry.example.com_X-Api-Key=key"},
+		{"CREDENTIAL_HELPER_HEADER_registry.example.com_Authorization=Basic not-base64"},
+	} {
+		helper, err := credentialhelperenv.New(environ)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exitCode, stdout := runDockerHelper(t, helper, "registry.example.com", "get")
+		if exitCode != 1 || !strings.Contains(stdout, "cannot be used by Docker") {
+			t.Errorf("expected error for %v, got exit code %d and %q", environ, exitCode, stdout)
+		}
+	}
+}
+
+func TestServerOtherCommands(t *testing.T) {
+	helper, err := credentialhelperenv.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exitCode, stdout := runDockerHelper(t, helper, "", "list"); exitCode != 0 || stdout != "{}\n" {
+		t.Errorf("unexpected result of list: exit code %d and %q", exitCode, stdout)
+	}
+	for _, command := range []string{"store", "erase", "unknown"} {
+		if exitCode, _ := runDockerHelper(t, helper, `{"ServerURL": "registry.example.com"}`, command); exitCode != 1 {
+			t.Errorf("expected %s to fail, got exit code %d", command, exitCode)
+		}
+	}
+	if exitCode, stdout := runDockerHelper(t, helper, ""); exitCode != 1 || !strings.Contains(stdout, "Usage: docker-credential-test") {
+		t.Errorf("expected usage, got exit code %d and %q", exitCode, stdout)
+	}
+}
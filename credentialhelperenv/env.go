@@ -0,0 +1,140 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelperenv provides a [credentialhelper.CredentialHelper]
+// returning static headers taken from environment variables, e.g. to pass
+// tokens to tools in CI without a dedicated credential helper.
+//
+// Headers are configured per host pattern (see
+// [credentialhelperrouter.ParsePattern]), either with one variable per header:
+//
+//	CREDENTIAL_HELPER_HEADER_*.example.com_Authorization=Bearer ...
+//
+// or with a JSON object in a single variable:
+//
+//	CREDENTIAL_HELPER_HEADERS_JSON={"*.example.com": {"Authorization": ["Bearer ..."]}}
+//
+// An empty pattern configures headers for all hosts not matched by any other
+// pattern. Requests are matched against the patterns like Bazel matches the
+// scopes of `--credential_helper`; headers of different patterns are not
+// combined.
+package credentialhelperenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperrouter"
+)
+
+const (
+	// HeaderVariablePrefix is the prefix of variables specifying a single
+	// header, followed by the host pattern, an underscore, and the name of
+	// the header. As the name of the header follows the last underscore, it
+	// must not contain underscores itself.
+	HeaderVariablePrefix = "CREDENTIAL_HELPER_HEADER_"
+
+	// HeadersJSONVariable is the variable specifying headers as a JSON object
+	// mapping host patterns to headers. Headers specified with
+	// [HeaderVariablePrefix] take precedence over those specified here.
+	HeadersJSONVariable = "CREDENTIAL_HELPER_HEADERS_JSON"
+)
+
+// New returns a [credentialhelper.CredentialHelper] returning the headers
+// configured in environ, which holds environment variables in the form
+// `key=value` like [os.Environ].
+func New(environ []string) (credentialhelper.CredentialHelper, error) {
+	headers := make(map[string]map[string][]string)
+	add := func(pattern string, name string, values []string) error {
+		if pattern != "" {
+			p, err := credentialhelperrouter.ParsePattern(pattern)
+			if err != nil {
+				return err
+			}
+			pattern = p.String()
+		}
+		if name == "" {
+			return fmt.Errorf("missing header name for pattern %q", pattern)
+		}
+		if headers[pattern] == nil {
+			headers[pattern] = make(map[string][]string)
+		}
+		headers[pattern][name] = values
+		return nil
+	}
+
+	for _, variable := range environ {
+		key, value, _ := strings.Cut(variable, "=")
+		if key != HeadersJSONVariable {
+			continue
+		}
+
+		var patterns map[string]map[string][]string
+		if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", HeadersJSONVariable, err)
+		}
+		for pattern, patternHeaders := range patterns {
+			for name, values := range patternHeaders {
+				if err := add(pattern, name, values); err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %w", HeadersJSONVariable, err)
+				}
+			}
+		}
+	}
+
+	for _, variable := range environ {
+		key, value, _ := strings.Cut(variable, "=")
+		suffix, ok := strings.CutPrefix(key, HeaderVariablePrefix)
+		if !ok {
+			continue
+		}
+
+		i := strings.LastIndexByte(suffix, '_')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid variable %s: expected %s<pattern>_<header>", key, HeaderVariablePrefix)
+		}
+		if err := add(suffix[:i], suffix[i+1:], []string{value}); err != nil {
+			return nil, fmt.Errorf("invalid variable %s: %w", key, err)
+		}
+	}
+
+	routes := make([]credentialhelperrouter.Route, 0, len(headers))
+	for pattern, patternHeaders := range headers {
+		routes = append(routes, credentialhelperrouter.Route{
+			Pattern: pattern,
+			Helper:  &staticCredentialHelper{headers: patternHeaders},
+		})
+	}
+	return credentialhelperrouter.New(routes)
+}
+
+// staticCredentialHelper returns the same headers for all requests.
+type staticCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	headers map[string][]string
+}
+
+func (h *staticCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	headers := make(map[string][]string, len(h.headers))
+	for name, values := range h.headers {
+		headers[name] = append([]string{}, values...)
+	}
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: headers,
+	}, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperenv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperenv"
+)
+
+func TestEnvCredentialHelper(t *testing.T) {
+	helper, err := credentialhelperenv.New([]string{
+		"PATH=/usr/bin",
+		`CREDENTIAL_HELPER_HEADERS_JSON={"*.example.com": {"Authorization": ["Bearer json"], "X-Tenant": ["a", "b"]}, "": {"Authorization": ["Bearer default"]}}`,
+		"CREDENTIAL_HELPER_HEADER_*.example.com_Authorization=Bearer wildcard",
+		"CREDENTIAL_HELPER_HEADER_foo.EXAMPLE.com_Authorization=Bearer exact=with=equals",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		uri  string
+		want map[string][]string
+	}{
+		{
+			uri: "https://bar.example.com/path",
+			want: map[string][]string{
+				"Authorization": {"Bearer wildcard"},
+				"X-Tenant":      {"a", "b"},
+			},
+		},
+		{
+			uri:  "https://foo.example.com:8443",
+			want: map[string][]string{"Authorization": {"Bearer exact=with=equals"}},
+		},
+		{
+			uri:  "https://example.org",
+			want: map[string][]string{"Authorization": {"Bearer default"}},
+		},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: tc.uri})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, response.Headers); diff != "" {
+				t.Errorf("unexpected headers (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEnvCredentialHelperNoMatch(t *testing.T) {
+	helper, err := credentialhelperenv.New([]string{
+		"CREDENTIAL_HELPER_HEADER_example.com_Authorization=Bearer token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.org"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestEnvCredentialHelperInvalid(t *testing.T) {
+	for _, variable := range []string{
+		"CREDENTIAL_HELPER_HEADER_Authorization=Bearer token",
+		"CREDENTIAL_HELPER_HEADER_example.com_=Bearer token",
+		"CREDENTIAL_HELPER_HEADER_*._Authorization=Bearer token",
+		`CREDENTIAL_HELPER_HEADERS_JSON={"example.com": {"Authorization": "Bearer token"}}`,
+		`CREDENTIAL_HELPER_HEADERS_JSON={"example..com": {"Authorization": ["Bearer token"]}}`,
+	} {
+		if _, err := credentialhelperenv.New([]string{variable}); err == nil {
+			t.Errorf("expected error for %q", variable)
+		}
+	}
+}
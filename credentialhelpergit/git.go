@@ -0,0 +1,235 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelpergit provides a [credentialhelper.CredentialHelper]
+// returning credentials stored for git, using git's credential helper
+// protocol.
+//
+// See https://git-scm.com/docs/git-credential for the protocol.
+package credentialhelpergit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+const (
+	// DefaultGit is the git executable used if none is configured.
+	DefaultGit = "git"
+
+	// helperPrefix is the prefix of the names of git credential helpers.
+	helperPrefix = "git-credential-"
+
+	// promptsDisabledMessage is written to stderr by `git credential fill`
+	// if it has no credentials and must not prompt for them.
+	promptsDisabledMessage = "terminal prompts disabled"
+)
+
+// Options represents options for the git credential bridge.
+type Options struct {
+	// Helper specifies the git credential helper to invoke directly, either
+	// by name (e.g., `store` for `git-credential-store`) or by path.
+	// Helpers configured with arguments or as shell snippets (`!...`) are
+	// not supported.
+	//
+	// If empty, `git credential fill` is invoked, which queries the helpers
+	// configured for git. It never prompts for credentials.
+	Helper string
+
+	// Git specifies the git executable.
+	//
+	// If empty, it defaults to [DefaultGit].
+	Git string
+
+	// UseHTTPPath specifies whether to pass the path of the requested URI to
+	// the helper, like git's `credential.useHttpPath` option.
+	UseHTTPPath bool
+
+	// Program specifies how git or the git credential helper is invoked.
+	Program credentialhelper.ClientOptions
+}
+
+// New returns a [credentialhelper.CredentialHelper] querying git's credential
+// helpers as configured in options.
+//
+// Credentials are returned as `Authorization` header: as `Basic`
+// credentials for usernames and passwords, or with the scheme given by
+// `authtype` for helpers supporting the `authtype` capability (e.g.,
+// `Bearer`). Requests for URIs without credentials fail with an error
+// wrapping [credentialhelper.ErrNoCredentials].
+func New(options Options) (credentialhelper.CredentialHelper, error) {
+	programOptions := options.Program
+	programOptions.Env = make(map[string]string, len(options.Program.Env)+1)
+	for name, value := range options.Program.Env {
+		programOptions.Env[name] = value
+	}
+
+	var path string
+	var args []string
+	if options.Helper == "" {
+		path = options.Git
+		if path == "" {
+			path = DefaultGit
+		}
+		args = []string{"credential", "fill"}
+		// Never prompt, neither on the terminal nor with any UI of the
+		// configured helpers.
+		programOptions.Env["GIT_TERMINAL_PROMPT"] = "0"
+		programOptions.Args = append(append([]string{}, programOptions.Args...), "-c", "credential.interactive=false")
+	} else {
+		path = options.Helper
+		if !strings.ContainsRune(path, filepath.Separator) && !strings.ContainsRune(path, '/') {
+			path = helperPrefix + path
+		}
+		args = []string{"get"}
+	}
+
+	program, err := credentialhelper.NewProgram(path, programOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &gitCredentialHelper{
+		program:     program,
+		args:        args,
+		useHTTPPath: options.UseHTTPPath,
+	}
+	return h, nil
+}
+
+type gitCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	program     *credentialhelper.Program
+	args        []string
+	useHTTPPath bool
+}
+
+// GetCredentials asks git or the git credential helper for credentials for
+// the requested URI.
+func (h *gitCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	u, err := url.Parse(request.URI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI %q: %w", request.URI, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("URI %q has no scheme or host", request.URI)
+	}
+
+	attributes := []attribute{
+		{"capability[]", "authtype"},
+		{"protocol", u.Scheme},
+		{"host", u.Host},
+	}
+	if h.useHTTPPath {
+		attributes = append(attributes, attribute{"path", strings.TrimPrefix(u.Path, "/")})
+	}
+	if u.User != nil {
+		attributes = append(attributes, attribute{"username", u.User.Username()})
+	}
+	stdin, err := encodeAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := h.program.Run(ctx, stdin, h.args...)
+	if err != nil {
+		var helperErr *credentialhelper.HelperError
+		if errors.As(err, &helperErr) && bytes.Contains(helperErr.Stderr, []byte(promptsDisabledMessage)) {
+			return nil, fmt.Errorf("%w: %w", credentialhelper.ErrNoCredentials, err)
+		}
+		return nil, err
+	}
+
+	credential := decodeAttributes(stdout)
+	if credential["quit"] == "1" || credential["quit"] == "true" {
+		return nil, fmt.Errorf("%w: git credential helper stopped the lookup for %s://%s", credentialhelper.ErrNoCredentials, u.Scheme, u.Host)
+	}
+
+	var authorization string
+	switch {
+	case credential["authtype"] != "" && credential["credential"] != "":
+		authorization = credential["authtype"] + " " + credential["credential"]
+	case credential["password"] != "":
+		token := base64.StdEncoding.EncodeToString([]byte(credential["username"] + ":" + credential["password"]))
+		authorization = "Basic " + token
+	default:
+		return nil, fmt.Errorf("%w: no git credentials for %s://%s", credentialhelper.ErrNoCredentials, u.Scheme, u.Host)
+	}
+
+	response := &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {authorization},
+		},
+	}
+	if expiry := credential["password_expiry_utc"]; expiry != "" {
+		seconds, err := strconv.ParseInt(expiry, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid password_expiry_utc %q: %w", expiry, err)
+		}
+		expires := time.Unix(seconds, 0)
+		response.Expires = &expires
+	}
+	return response, nil
+}
+
+// attribute represents a line of the git credential protocol.
+type attribute struct {
+	key   string
+	value string
+}
+
+// encodeAttributes encodes attributes in the git credential protocol.
+func encodeAttributes(attributes []attribute) ([]byte, error) {
+	var b bytes.Buffer
+	for _, a := range attributes {
+		if strings.ContainsAny(a.value, "\x00\n") {
+			return nil, fmt.Errorf("git credential attribute %s must not contain newlines or NUL characters", a.key)
+		}
+		b.WriteString(a.key + "=" + a.value + "\n")
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+// decodeAttributes decodes the attributes in data, which is in the git
+// credential protocol. Multi-valued attributes (e.g., `capability[]`) are
+// ignored.
+func decodeAttributes(data []byte) map[string]string {
+	attributes := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.HasSuffix(key, "[]") {
+			continue
+		}
+		attributes[key] = value
+	}
+	return attributes
+}
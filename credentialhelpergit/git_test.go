@@ -0,0 +1,134 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelpergit_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelpergit"
+)
+
+func basic(username string, password string) []string {
+	return []string{"Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))}
+}
+
+func TestGitCredentialFill(t *testing.T) {
+	helper, err := credentialhelpergit.New(credentialhelpergit.Options{Git: "testdata/git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com/repo.git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(basic("alice", "secret"), response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+	if response.Expires == nil || !response.Expires.Equal(time.Unix(981173106, 0)) {
+		t.Errorf("unexpected expiry %v", response.Expires)
+	}
+
+	response, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://bearer.example.com:8443/artifacts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Bearer token"}, response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+}
+
+func TestGitCredentialFillNoCredentials(t *testing.T) {
+	helper, err := credentialhelpergit.New(credentialhelpergit.Options{Git: "testdata/git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://unknown.example.com"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestGitCredentialHelper(t *testing.T) {
+	helper, err := credentialhelpergit.New(credentialhelpergit.Options{Helper: "testdata/git-credential-fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com/repo.git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(basic("bob", "path:unset"), response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+
+	helper, err = credentialhelpergit.New(credentialhelpergit.Options{Helper: "testdata/git-credential-fake", UseHTTPPath: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://carol@example.com/repo.git"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(basic("carol", "path:repo.git"), response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+
+	for _, uri := range []string{"https://quit.example.com", "https://unknown.example.com"} {
+		_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+			t.Errorf("expected error wrapping ErrNoCredentials for %q, got %v", uri, err)
+		}
+	}
+}
+
+func TestGitCredentialHelperByName(t *testing.T) {
+	testdata, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", testdata)
+
+	if _, err := credentialhelpergit.New(credentialhelpergit.Options{Helper: "fake"}); err != nil {
+		t.Errorf("expected git-credential-fake to be found on PATH, got %v", err)
+	}
+}
+
+func TestGitCredentialInvalidURI(t *testing.T) {
+	helper, err := credentialhelpergit.New(credentialhelpergit.Options{Helper: "testdata/git-credential-fake", UseHTTPPath: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uri := range []string{"example.com", "https://example.com/%0Apath"} {
+		_, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if err == nil {
+			t.Errorf("expected error for %q", uri)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelpergoauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// unauthorizedResponse is passed to `GOAUTH` commands on stdin, as if a
+// request for the URI credentials are requested for had failed.
+const unauthorizedResponse = "HTTP/1.1 401 Unauthorized\r\n\r\n"
+
+// NewClient returns a [credentialhelper.CredentialHelper] running the
+// `GOAUTH` command command, a space-separated argument list as in the
+// `command` form of `GOAUTH`, with options.
+//
+// For every request, the command is invoked like the Go toolchain does after
+// a request failed: with the requested URI as additional argument and a
+// `401 Unauthorized` response on stdin. Like the Go toolchain, the headers of
+// the credential set it prints with the longest prefix of the URI are
+// returned. If there is none, the request fails with an error wrapping
+// [credentialhelper.ErrNoCredentials].
+func NewClient(command string, options credentialhelper.ClientOptions) (credentialhelper.CredentialHelper, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("missing GOAUTH command")
+	}
+	options.Args = append(append([]string{}, options.Args...), fields[1:]...)
+
+	program, err := credentialhelper.NewProgram(fields[0], options)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &goauthClient{
+		program: program,
+	}
+	return c, nil
+}
+
+type goauthClient struct {
+	credentialhelper.CredentialHelperBase
+
+	program *credentialhelper.Program
+}
+
+// GetCredentials runs the `GOAUTH` command to fetch credentials.
+func (c *goauthClient) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	stdout, err := c.program.Run(ctx, []byte(unauthorizedResponse), request.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := ParseCredentialSets(stdout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output of GOAUTH command %q: %w", c.program.Path(), err)
+	}
+	var match *CredentialSet
+	matchLength := -1
+	for i := range sets {
+		if length := sets[i].matchLength(request.URI); length > matchLength {
+			match = &sets[i]
+			matchLength = length
+		}
+	}
+	if match != nil {
+		return &credentialhelper.GetCredentialsResponse{
+			Headers: match.Headers,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: GOAUTH command %q returned no credentials for %q", credentialhelper.ErrNoCredentials, c.program.Path(), request.URI)
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelpergoauth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelpergoauth"
+)
+
+func TestClient(t *testing.T) {
+	helper, err := credentialhelpergoauth.NewClient("testdata/goauth.sh --flag", credentialhelper.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The credentials for https://example.com/private/ win over the earlier
+	// ones for https://example.com, since their prefix is longer.
+	for uri, want := range map[string]map[string][]string{
+		"https://example.com/private/mod/@v/list": {
+			"Authorization": {"Bearer private"},
+		},
+		"https://example.com/public/mod/@v/list": {
+			"Authorization": {"Bearer public"},
+			"X-Args":        {"--flag https://example.com/public/mod/@v/list"},
+			"X-Status":      {"HTTP/1.1 401 Unauthorized"},
+		},
+	} {
+		response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, response.Headers); diff != "" {
+			t.Errorf("unexpected headers for %q (-want +got):\n%s", uri, diff)
+		}
+	}
+
+	_, err = helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.org"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestNewClientErrors(t *testing.T) {
+	for _, command := range []string{"", "  ", "testdata/missing.sh"} {
+		if _, err := credentialhelpergoauth.NewClient(command, credentialhelper.ClientOptions{}); err == nil {
+			t.Errorf("expected error for %q", command)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelpergoauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// StartCommand turns the current process into a `GOAUTH` command serving
+// credentials from helper.
+//
+// Every argument is a URL to print credentials for. The Go toolchain invokes
+// the command with the arguments configured in `GOAUTH` before its first
+// request, so these can be used to provide credentials upfront (e.g.,
+// `GOAUTH='my-helper https://proxy.example.com'`). If a request fails, it
+// invokes the command again with the URL of the request appended, and the
+// response on stdin, which is ignored. URLs helper has no credentials for are
+// skipped.
+//
+// The context passed to the helper is canceled when the process receives
+// SIGINT or SIGTERM. A deadline can be set with the `--timeout=<duration>`
+// option before the URLs or with
+// [credentialhelper.TimeoutEnvironmentVariable].
+//
+// This function never returns.
+func StartCommand(helper credentialhelper.CredentialHelper) {
+	timeout, args, err := credentialhelper.ParseTimeout(os.Args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	credentialhelper.RunMain(timeout, func(ctx context.Context) int {
+		return startCommand(ctx, os.Stdin, os.Stdout, os.Stderr, args, helper)
+	})
+}
+
+func startCommand(ctx context.Context, stdin io.Reader, stdout io.Writer, stderr io.Writer, args []string, helper credentialhelper.CredentialHelper) int {
+	// The response of the failed request is not needed, but the Go toolchain
+	// expects it to be consumed.
+	io.Copy(io.Discard, stdin)
+
+	var sets []CredentialSet
+	for _, url := range args[1:] {
+		response, err := helper.GetCredentials(ctx, &credentialhelper.GetCredentialsRequest{URI: url})
+		if errors.Is(err, credentialhelper.ErrNoCredentials) {
+			continue
+		} else if err != nil {
+			fmt.Fprintln(stderr, err.Error())
+			return 1
+		}
+
+		sets = append(sets, CredentialSet{
+			URLs:    []string{url},
+			Headers: response.Headers,
+		})
+	}
+
+	output, err := FormatCredentialSets(sets)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	if _, err := stdout.Write(output); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelpergoauth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/EngFlow/credential-helper-go/credentialhelperenv"
+)
+
+func runCommand(t *testing.T, stdin string, environ []string, args ...string) (int, string, string) {
+	t.Helper()
+
+	helper, err := credentialhelperenv.New(environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	exitCode := startCommand(context.Background(), strings.NewReader(stdin), &stdout, &stderr, append([]string{"goauth"}, args...), helper)
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func TestCommand(t *testing.T) {
+	environ := []string{
+		"CREDENTIAL_HELPER_HEADER_proxy.example.com_Authorization=Bearer proxy",
+		"CREDENTIAL_HELPER_HEADER_*.example.org_Authorization=Bearer org",
+	}
+
+	// Initial invocation with the configured arguments only.
+	exitCode, stdout, stderr := runCommand(t, "", environ, "https://proxy.example.com")
+	if exitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", exitCode, stderr)
+	}
+	want := "https://proxy.example.com\n\nAuthorization: Bearer proxy\n\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	// Invocation after a failed request, skipping URLs without credentials.
+	exitCode, stdout, stderr = runCommand(t, "HTTP/1.1 401 Unauthorized\r\n\r\n", environ,
+		"https://unknown.example.com", "https://git.example.org/repo/@v/list")
+	if exitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", exitCode, stderr)
+	}
+	want = "https://git.example.org/repo/@v/list\n\nAuthorization: Bearer org\n\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+}
+
+func TestCommandNoArguments(t *testing.T) {
+	exitCode, stdout, stderr := runCommand(t, "", nil)
+	if exitCode != 0 || stdout != "" {
+		t.Errorf("expected no output, got exit code %d, %q and %q", exitCode, stdout, stderr)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	exitCode, _, stderr := runCommand(t, "", []string{"CREDENTIAL_HELPER_HEADER__Authorization=Bearer token"}, "not a url")
+	if exitCode != 1 || stderr == "" {
+		t.Errorf("expected error for invalid URL, got exit code %d and %q", exitCode, stderr)
+	}
+}
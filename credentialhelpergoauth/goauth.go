@@ -0,0 +1,132 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelpergoauth adapts between credential helpers and
+// commands for the `GOAUTH` environment variable of the Go toolchain (Go
+// 1.24 and later), so that the Go toolchain can use the same credentials as
+// Bazel and vice versa.
+//
+// See `go help goauth` for the protocol.
+package credentialhelpergoauth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// CredentialSet represents headers the Go toolchain attaches to requests for
+// URLs starting with any of a set of prefixes.
+type CredentialSet struct {
+	// URLs are the prefixes of the URLs the headers apply to. They must
+	// start with `https://`.
+	URLs []string
+
+	// Headers are the headers to attach to requests.
+	Headers map[string][]string
+}
+
+// Matches reports whether the credentials apply to url: whether url equals
+// one of the prefixes or continues it with a new path segment, as the Go
+// toolchain matches them.
+func (s *CredentialSet) Matches(url string) bool {
+	return s.matchLength(url) >= 0
+}
+
+// matchLength returns the length of the longest prefix of the set matching
+// url, or -1 if none does.
+func (s *CredentialSet) matchLength(url string) int {
+	length := -1
+	for _, prefix := range s.URLs {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if (url == prefix || strings.HasPrefix(url, prefix+"/")) && len(prefix) > length {
+			length = len(prefix)
+		}
+	}
+	return length
+}
+
+// ParseCredentialSets parses the output of a `GOAUTH` command.
+func ParseCredentialSets(data []byte) ([]CredentialSet, error) {
+	var sets []CredentialSet
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		var set CredentialSet
+		for {
+			line, err := reader.ReadLine()
+			if err != nil {
+				if len(set.URLs) == 0 {
+					// Trailing blank lines and the end of input are fine
+					// between credential sets.
+					return sets, nil
+				}
+				return nil, fmt.Errorf("missing headers for %q", set.URLs)
+			}
+			if line == "" {
+				if len(set.URLs) == 0 {
+					continue
+				}
+				break
+			}
+			if !strings.HasPrefix(line, "https://") {
+				return nil, fmt.Errorf("invalid URL line %q: must start with https://", line)
+			}
+			set.URLs = append(set.URLs, line)
+		}
+
+		headers, err := reader.ReadMIMEHeader()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid headers for %q: %w", set.URLs, err)
+		}
+		set.Headers = headers
+		sets = append(sets, set)
+	}
+}
+
+// FormatCredentialSets formats sets as output of a `GOAUTH` command.
+func FormatCredentialSets(sets []CredentialSet) ([]byte, error) {
+	var b bytes.Buffer
+	for _, set := range sets {
+		if len(set.URLs) == 0 {
+			return nil, fmt.Errorf("credential set without URLs")
+		}
+		for _, url := range set.URLs {
+			if !strings.HasPrefix(url, "https://") || strings.ContainsAny(url, "\r\n") {
+				return nil, fmt.Errorf("invalid URL %q: must start with https:// and be a single line", url)
+			}
+			b.WriteString(url + "\n")
+		}
+		b.WriteString("\n")
+
+		names := make([]string, 0, len(set.Headers))
+		for name := range set.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range set.Headers[name] {
+				if strings.ContainsAny(name+value, "\r\n") {
+					return nil, fmt.Errorf("header %q must be a single line", name)
+				}
+				b.WriteString(name + ": " + value + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes(), nil
+}
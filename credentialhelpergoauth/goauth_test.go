@@ -0,0 +1,117 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelpergoauth_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/EngFlow/credential-helper-go/credentialhelpergoauth"
+)
+
+func TestParseCredentialSets(t *testing.T) {
+	sets, err := credentialhelpergoauth.ParseCredentialSets([]byte(`https://example.com
+https://example.net/api/
+
+Authorization: Basic dXNlcjpwYXNz
+
+https://another-example.org/
+
+Example: Data
+Example: More
+
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []credentialhelpergoauth.CredentialSet{
+		{
+			URLs:    []string{"https://example.com", "https://example.net/api/"},
+			Headers: map[string][]string{"Authorization": {"Basic dXNlcjpwYXNz"}},
+		},
+		{
+			URLs:    []string{"https://another-example.org/"},
+			Headers: map[string][]string{"Example": {"Data", "More"}},
+		},
+	}
+	if diff := cmp.Diff(want, sets); diff != "" {
+		t.Errorf("unexpected credential sets (-want +got):\n%s", diff)
+	}
+
+	formatted, err := credentialhelpergoauth.FormatCredentialSets(sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := credentialhelpergoauth.ParseCredentialSets(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, reparsed); diff != "" {
+		t.Errorf("unexpected credential sets after formatting (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseCredentialSetsEmpty(t *testing.T) {
+	for _, data := range []string{"", "\n\n"} {
+		sets, err := credentialhelpergoauth.ParseCredentialSets([]byte(data))
+		if err != nil || len(sets) != 0 {
+			t.Errorf("expected no credential sets for %q, got %v and %v", data, sets, err)
+		}
+	}
+}
+
+func TestParseCredentialSetsErrors(t *testing.T) {
+	for _, data := range []string{
+		"http://example.com\n\nAuthorization: Basic foo\n\n",
+		"https://example.com\n",
+		"https://example.com\n\nnot a header\n\n",
+	} {
+		if _, err := credentialhelpergoauth.ParseCredentialSets([]byte(data)); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestFormatCredentialSetsErrors(t *testing.T) {
+	for _, set := range []credentialhelpergoauth.CredentialSet{
+		{},
+		{URLs: []string{"http://example.com"}},
+		{URLs: []string{"https://example.com"}, Headers: map[string][]string{"Authorization": {"Bearer a\nb"}}},
+	} {
+		if _, err := credentialhelpergoauth.FormatCredentialSets([]credentialhelpergoauth.CredentialSet{set}); err == nil {
+			t.Errorf("expected error for %v", set)
+		}
+	}
+}
+
+func TestCredentialSetMatches(t *testing.T) {
+	set := credentialhelpergoauth.CredentialSet{
+		URLs: []string{"https://example.com/api/", "https://example.net"},
+	}
+	for url, want := range map[string]bool{
+		"https://example.com/api":         true,
+		"https://example.com/api/foo":     true,
+		"https://example.com/apis":        false,
+		"https://example.com":             false,
+		"https://example.net/foo/@v/list": true,
+		"https://example.network":         false,
+	} {
+		if got := set.Matches(url); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
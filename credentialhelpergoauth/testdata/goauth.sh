@@ -0,0 +1,19 @@
+#!/usr/bin/env bash
+
+# Fake GOAUTH command printing credentials for example.com, and echoing its
+# arguments and the status line of its input for other URLs.
+status="$(head -n 1 | tr -d '\r')"
+
+cat <<OUTPUT
+https://example.com
+
+Authorization: Bearer public
+X-Args: $*
+X-Status: $status
+
+https://other.example.com
+https://example.com/private/
+
+Authorization: Bearer private
+
+OUTPUT
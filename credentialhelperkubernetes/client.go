@@ -0,0 +1,142 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperkubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// ExecConfig describes how to run a plugin, like the `exec` entry of a
+// kubeconfig user.
+type ExecConfig struct {
+	// Command is the plugin executable.
+	Command string
+
+	// Args are the arguments passed to the plugin.
+	Args []string
+
+	// Env specifies additional environment variables for the plugin.
+	Env map[string]string
+
+	// APIVersion is the version of the protocol to use.
+	//
+	// If empty, it defaults to [APIVersionV1].
+	APIVersion string
+
+	// ProvideClusterInfo specifies whether to pass the scheme and host of
+	// the requested URI to the plugin as the server of the cluster.
+	ProvideClusterInfo bool
+
+	// Program specifies how the plugin is invoked. Its Args and Env are
+	// extended by those above.
+	Program credentialhelper.ClientOptions
+}
+
+// NewClient returns a [credentialhelper.CredentialHelper] running the plugin
+// described by config for every request, and returning the token it returns
+// as `Bearer` `Authorization` header, expiring when the token does.
+//
+// Plugins returning client certificates are not supported.
+func NewClient(config ExecConfig) (credentialhelper.CredentialHelper, error) {
+	if config.APIVersion == "" {
+		config.APIVersion = APIVersionV1
+	} else if !isSupportedAPIVersion(config.APIVersion) {
+		return nil, fmt.Errorf("unsupported API version %q", config.APIVersion)
+	}
+
+	// Ensure the plugin exists, so that misconfigurations fail early.
+	if _, err := credentialhelper.NewProgram(config.Command, config.Program); err != nil {
+		return nil, err
+	}
+
+	c := &execPluginClient{
+		config: config,
+	}
+	return c, nil
+}
+
+type execPluginClient struct {
+	credentialhelper.CredentialHelperBase
+
+	config ExecConfig
+}
+
+// GetCredentials runs the plugin to fetch credentials.
+func (c *execPluginClient) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	execInfo := ExecCredential{
+		APIVersion: c.config.APIVersion,
+		Kind:       execCredentialKind,
+		Spec:       &ExecCredentialSpec{},
+	}
+	if c.config.ProvideClusterInfo {
+		u, err := url.Parse(request.URI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI %q: %w", request.URI, err)
+		}
+		execInfo.Spec.Cluster = &Cluster{
+			Server: (&url.URL{Scheme: u.Scheme, Host: u.Host}).String(),
+		}
+	}
+	execInfoJSON, err := json.Marshal(execInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	options := c.config.Program
+	options.Args = append(append([]string{}, options.Args...), c.config.Args...)
+	options.Env = make(map[string]string)
+	for name, value := range c.config.Program.Env {
+		options.Env[name] = value
+	}
+	for name, value := range c.config.Env {
+		options.Env[name] = value
+	}
+	options.Env[ExecInfoEnvironmentVariable] = string(execInfoJSON)
+
+	program, err := credentialhelper.NewProgram(c.config.Command, options)
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := program.Run(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var credential ExecCredential
+	if err := json.Unmarshal(stdout, &credential); err != nil {
+		return nil, fmt.Errorf("invalid response from plugin %q: %w", c.config.Command, err)
+	}
+	if credential.Kind != execCredentialKind || credential.APIVersion != c.config.APIVersion {
+		return nil, fmt.Errorf("plugin %q returned %s %s, expected %s %s", c.config.Command, credential.APIVersion, credential.Kind, c.config.APIVersion, execCredentialKind)
+	}
+	if credential.Status == nil || credential.Status.Token == "" {
+		if credential.Status != nil && credential.Status.ClientCertificateData != "" {
+			return nil, fmt.Errorf("plugin %q returned a client certificate, which is not supported", c.config.Command)
+		}
+		return nil, fmt.Errorf("plugin %q returned no token", c.config.Command)
+	}
+
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {"Bearer " + credential.Status.Token},
+		},
+		Expires: credential.Status.ExpirationTimestamp,
+	}, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelperkubernetes_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperkubernetes"
+)
+
+func TestClient(t *testing.T) {
+	client, err := credentialhelperkubernetes.NewClient(credentialhelperkubernetes.ExecConfig{
+		Command:            "testdata/exec-plugin.sh",
+		Env:                map[string]string{"FOO": "bar"},
+		ProvideClusterInfo: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://k8s.example.com:6443/api/v1/pods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {"Bearer https://k8s.example.com:6443:bar"},
+		},
+		Expires: &expires,
+	}
+	if diff := cmp.Diff(want, response); diff != "" {
+		t.Errorf("unexpected response (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientWithoutClusterInfo(t *testing.T) {
+	client, err := credentialhelperkubernetes.NewClient(credentialhelperkubernetes.ExecConfig{
+		Command: "testdata/exec-plugin.sh",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://k8s.example.com:6443/api/v1/pods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Bearer no-server:unset"}, response.Headers["Authorization"]); diff != "" {
+		t.Errorf("unexpected headers (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"certificate"}, want: "client certificate"},
+		{args: []string{"wrong-version"}, want: "expected client.authentication.k8s.io/v1 ExecCredential"},
+		{args: []string{"nothing"}, want: "invalid response"},
+	} {
+		client, err := credentialhelperkubernetes.NewClient(credentialhelperkubernetes.ExecConfig{
+			Command: "testdata/exec-plugin.sh",
+			Args:    tc.args,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = client.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://k8s.example.com:6443/api/v1/pods"})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("expected error containing %q for %v, got %v", tc.want, tc.args, err)
+		}
+	}
+}
+
+func TestNewClientErrors(t *testing.T) {
+	if _, err := credentialhelperkubernetes.NewClient(credentialhelperkubernetes.ExecConfig{Command: "testdata/missing.sh"}); err == nil {
+		t.Error("expected error for missing plugin")
+	}
+	if _, err := credentialhelperkubernetes.NewClient(credentialhelperkubernetes.ExecConfig{Command: "testdata/exec-plugin.sh", APIVersion: "v2"}); err == nil {
+		t.Error("expected error for unsupported API version")
+	}
+}
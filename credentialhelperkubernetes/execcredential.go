@@ -0,0 +1,84 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelperkubernetes adapts between credential helpers and
+// Kubernetes client-go credential plugins (`exec` entries of a kubeconfig).
+//
+// See https://kubernetes.io/docs/reference/access-authn-authz/authentication/#client-go-credential-plugins
+// for the protocol.
+package credentialhelperkubernetes
+
+import (
+	"time"
+)
+
+const (
+	// APIVersionV1 is the API version of the stable plugin protocol.
+	APIVersionV1 = "client.authentication.k8s.io/v1"
+
+	// APIVersionV1Beta1 is the API version of the beta plugin protocol.
+	APIVersionV1Beta1 = "client.authentication.k8s.io/v1beta1"
+
+	// ExecInfoEnvironmentVariable passes the [ExecCredential] describing the
+	// request to the plugin.
+	ExecInfoEnvironmentVariable = "KUBERNETES_EXEC_INFO"
+
+	// execCredentialKind is the kind of [ExecCredential] objects.
+	execCredentialKind = "ExecCredential"
+)
+
+// ExecCredential is passed to plugins in [ExecInfoEnvironmentVariable] and
+// returned by them on stdout.
+type ExecCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Spec       *ExecCredentialSpec   `json:"spec,omitempty"`
+	Status     *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// ExecCredentialSpec describes the request to a plugin.
+type ExecCredentialSpec struct {
+	// Cluster describes the cluster credentials are requested for, if the
+	// plugin is configured with `provideClusterInfo`.
+	Cluster *Cluster `json:"cluster,omitempty"`
+
+	// Interactive specifies whether the plugin may prompt the user.
+	Interactive bool `json:"interactive"`
+}
+
+// Cluster describes a cluster.
+type Cluster struct {
+	// Server is the URL of the cluster (e.g., `https://k8s.example.com`).
+	Server string `json:"server"`
+}
+
+// ExecCredentialStatus holds the credentials returned by a plugin.
+type ExecCredentialStatus struct {
+	// ExpirationTimestamp is the time the credentials expire, if any.
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+
+	// Token is a bearer token.
+	Token string `json:"token,omitempty"`
+
+	// ClientCertificateData and ClientKeyData are a PEM-encoded client
+	// certificate and key, which cannot be represented as headers.
+	ClientCertificateData string `json:"clientCertificateData,omitempty"`
+	ClientKeyData         string `json:"clientKeyData,omitempty"`
+}
+
+// isSupportedAPIVersion returns whether apiVersion is a version of the plugin
+// protocol supported by this package.
+func isSupportedAPIVersion(apiVersion string) bool {
+	return apiVersion == APIVersionV1 || apiVersion == APIVersionV1Beta1
+}
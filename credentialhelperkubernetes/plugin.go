@@ -0,0 +1,119 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperkubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// StartExecPlugin turns the current process into a Kubernetes credential
+// plugin serving tokens from helper.
+//
+// Credentials are requested for the URI given as the only argument, or else
+// for the server of the cluster, which requires the plugin to be configured
+// with `provideClusterInfo: true`. The response must consist of a single
+// `Bearer` `Authorization` header; its expiry is passed on.
+//
+// The context passed to the helper is canceled when the process receives
+// SIGINT or SIGTERM. A deadline can be set with the `--timeout=<duration>`
+// option before the URI or with
+// [credentialhelper.TimeoutEnvironmentVariable].
+//
+// This function never returns.
+func StartExecPlugin(helper credentialhelper.CredentialHelper) {
+	timeout, args, err := credentialhelper.ParseTimeout(os.Args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	credentialhelper.RunMain(timeout, func(ctx context.Context) int {
+		return startExecPlugin(ctx, os.Getenv(ExecInfoEnvironmentVariable), os.Stdout, os.Stderr, args, helper)
+	})
+}
+
+func startExecPlugin(ctx context.Context, execInfo string, stdout io.Writer, stderr io.Writer, args []string, helper credentialhelper.CredentialHelper) int {
+	credential, err := execPlugin(ctx, execInfo, args[1:], helper)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+
+	if err := json.NewEncoder(stdout).Encode(credential); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+// execPlugin returns the [ExecCredential] for the request described by
+// execInfo, the value of [ExecInfoEnvironmentVariable].
+func execPlugin(ctx context.Context, execInfo string, args []string, helper credentialhelper.CredentialHelper) (*ExecCredential, error) {
+	request := ExecCredential{
+		APIVersion: APIVersionV1,
+	}
+	if execInfo != "" {
+		if err := json.Unmarshal([]byte(execInfo), &request); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", ExecInfoEnvironmentVariable, err)
+		}
+		if !isSupportedAPIVersion(request.APIVersion) {
+			return nil, fmt.Errorf("unsupported API version %q", request.APIVersion)
+		}
+	}
+
+	var uri string
+	switch {
+	case len(args) > 1:
+		return nil, errors.New("expected at most one argument, the URI to get credentials for")
+	case len(args) == 1:
+		uri = args[0]
+	case request.Spec != nil && request.Spec.Cluster != nil && request.Spec.Cluster.Server != "":
+		uri = request.Spec.Cluster.Server
+	default:
+		return nil, errors.New("missing URI: pass it as argument or set provideClusterInfo")
+	}
+
+	response, err := helper.GetCredentials(ctx, &credentialhelper.GetCredentialsRequest{URI: uri})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := response.AuthorizationCredentials("Bearer")
+	if err != nil {
+		return nil, fmt.Errorf("credentials for %q cannot be used by Kubernetes: %w", uri, err)
+	}
+
+	status := &ExecCredentialStatus{
+		Token: token,
+	}
+	if response.Expires != nil {
+		// Kubernetes timestamps have a precision of seconds; round down so
+		// that the token is not used after it expired.
+		expires := response.Expires.Truncate(time.Second)
+		status.ExpirationTimestamp = &expires
+	}
+	return &ExecCredential{
+		APIVersion: request.APIVersion,
+		Kind:       execCredentialKind,
+		Status:     status,
+	}, nil
+}
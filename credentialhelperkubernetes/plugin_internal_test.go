@@ -0,0 +1,120 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperkubernetes
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// staticCredentialHelper returns a fixed response, recording the URI of the
+// last request.
+type staticCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	response *credentialhelper.GetCredentialsResponse
+	uri      string
+}
+
+func (h *staticCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	h.uri = request.URI
+	return h.response, nil
+}
+
+func bearerHelper(expires *time.Time) *staticCredentialHelper {
+	return &staticCredentialHelper{
+		response: &credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{"authorization": {"Bearer token"}},
+			Expires: expires,
+		},
+	}
+}
+
+func runExecPlugin(t *testing.T, helper credentialhelper.CredentialHelper, execInfo string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := startExecPlugin(context.Background(), execInfo, &stdout, &stderr, append([]string{"plugin"}, args...), helper)
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func TestExecPlugin(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 999, time.UTC)
+	helper := bearerHelper(&expires)
+
+	exitCode, stdout, stderr := runExecPlugin(t, helper,
+		`{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","spec":{"cluster":{"server":"https://k8s.example.com"},"interactive":false}}`)
+	if exitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", exitCode, stderr)
+	}
+
+	want := `{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":{"expirationTimestamp":"2030-01-02T03:04:05Z","token":"token"}}` + "\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+	if helper.uri != "https://k8s.example.com" {
+		t.Errorf("expected request for server of cluster, got %q", helper.uri)
+	}
+}
+
+func TestExecPluginURIArgument(t *testing.T) {
+	helper := bearerHelper(nil)
+
+	exitCode, stdout, stderr := runExecPlugin(t, helper, "", "https://other.example.com")
+	if exitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", exitCode, stderr)
+	}
+
+	want := `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"token"}}` + "\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+	if helper.uri != "https://other.example.com" {
+		t.Errorf("expected request for URI argument, got %q", helper.uri)
+	}
+}
+
+func TestExecPluginErrors(t *testing.T) {
+	basicHelper := &staticCredentialHelper{
+		response: &credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{"Authorization": {"Basic dXNlcjpwYXNz"}},
+		},
+	}
+
+	for _, tc := range []struct {
+		helper   credentialhelper.CredentialHelper
+		execInfo string
+		args     []string
+		want     string
+	}{
+		{helper: bearerHelper(nil), want: "missing URI"},
+		{helper: bearerHelper(nil), args: []string{"a", "b"}, want: "at most one argument"},
+		{helper: bearerHelper(nil), execInfo: "{", want: "invalid value for KUBERNETES_EXEC_INFO"},
+		{helper: bearerHelper(nil), execInfo: `{"apiVersion":"v2"}`, want: "unsupported API version"},
+		{helper: basicHelper, args: []string{"https://k8s.example.com"}, want: "cannot be used by Kubernetes"},
+	} {
+		exitCode, _, stderr := runExecPlugin(t, tc.helper, tc.execInfo, tc.args...)
+		if exitCode != 1 || !strings.Contains(stderr, tc.want) {
+			t.Errorf("expected error containing %q, got exit code %d and %q", tc.want, exitCode, stderr)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+#!/usr/bin/env bash
+
+server="$(printf '%s' "$KUBERNETES_EXEC_INFO" | sed -n 's/.*"server":"\([^"]*\)".*/\1/p')"
+
+case "${1-token}" in
+  token)
+    echo "{\"apiVersion\":\"client.authentication.k8s.io/v1\",\"kind\":\"ExecCredential\",\"status\":{\"token\":\"${server:-no-server}:${FOO-unset}\",\"expirationTimestamp\":\"2030-01-02T03:04:05Z\"}}"
+    ;;
+  certificate)
+    echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"clientCertificateData":"cert","clientKeyData":"key"}}'
+    ;;
+  wrong-version)
+    echo '{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":{"token":"token"}}'
+    ;;
+esac
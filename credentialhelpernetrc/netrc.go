@@ -0,0 +1,322 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelpernetrc provides a [credentialhelper.CredentialHelper]
+// returning `Basic` credentials from a `.netrc` file, like Bazel's
+// downloader does.
+package credentialhelpernetrc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperrouter"
+)
+
+// PathEnvironmentVariable specifies the path of the `.netrc` file, overriding
+// the default location in the home directory.
+const PathEnvironmentVariable = "NETRC"
+
+// Machine represents the credentials for a host in a `.netrc` file.
+type Machine struct {
+	// Name is the name of the host, or empty for the `default` entry.
+	Name string
+
+	Login    string
+	Password string
+	Account  string
+}
+
+// Netrc represents the contents of a `.netrc` file.
+type Netrc struct {
+	// Machines are the entries for specific hosts, in the order they appear
+	// in the file.
+	Machines []Machine
+
+	// Default is the `default` entry, if any.
+	Default *Machine
+}
+
+// DefaultPath returns the path of the `.netrc` file of the current user: the
+// value of [PathEnvironmentVariable] if set, and `.netrc` (`_netrc` on
+// Windows) in the home directory otherwise.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(PathEnvironmentVariable); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(home, name), nil
+}
+
+// Load parses the `.netrc` file at path.
+func Load(path string) (*Netrc, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	n, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return n, nil
+}
+
+// Parse parses the contents of a `.netrc` file.
+//
+// Tokens may be quoted with double quotes, in which backslashes escape the
+// next character. Comments start with `#` and extend to the end of the line.
+// Macro definitions (`macdef`) are skipped.
+func Parse(r io.Reader) (*Netrc, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tokenizer{data: string(data), line: 1}
+	n := &Netrc{}
+	var current *Machine
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if current.Name == "" {
+			n.Default = current
+		} else {
+			n.Machines = append(n.Machines, *current)
+		}
+		current = nil
+	}
+
+	for {
+		token, ok, err := t.next()
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+
+		switch token {
+		case "machine":
+			name, err := t.value(token)
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			current = &Machine{Name: strings.ToLower(name)}
+
+		case "default":
+			flush()
+			current = &Machine{}
+
+		case "login", "password", "account":
+			value, err := t.value(token)
+			if err != nil {
+				return nil, err
+			}
+			if current == nil {
+				return nil, fmt.Errorf("line %d: %q outside of machine entry", t.line, token)
+			}
+			switch token {
+			case "login":
+				current.Login = value
+			case "password":
+				current.Password = value
+			case "account":
+				current.Account = value
+			}
+
+		case "macdef":
+			if _, err := t.value(token); err != nil {
+				return nil, err
+			}
+			t.skipMacro()
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected token %q", t.line, token)
+		}
+	}
+	flush()
+	return n, nil
+}
+
+// Lookup returns the entry for host: the first entry for exactly that host,
+// the `default` entry, or nil if there is neither.
+func (n *Netrc) Lookup(host string) *Machine {
+	host = strings.ToLower(host)
+	for i := range n.Machines {
+		if n.Machines[i].Name == host {
+			return &n.Machines[i]
+		}
+	}
+	return n.Default
+}
+
+// tokenizer splits the contents of a `.netrc` file into tokens.
+type tokenizer struct {
+	data string
+	pos  int
+
+	// line is the line at pos, starting at 1.
+	line int
+}
+
+// next returns the next token, or false at the end of the input.
+func (t *tokenizer) next() (string, bool, error) {
+	for t.pos < len(t.data) {
+		c := t.data[t.pos]
+		switch {
+		case c == '\n':
+			t.pos++
+			t.line++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			t.pos++
+		case c == '#':
+			for t.pos < len(t.data) && t.data[t.pos] != '\n' {
+				t.pos++
+			}
+		default:
+			return t.word()
+		}
+	}
+	return "", false, nil
+}
+
+// word reads the token at the current position.
+func (t *tokenizer) word() (string, bool, error) {
+	line := t.line
+	var b strings.Builder
+	if t.data[t.pos] == '"' {
+		t.pos++
+		for {
+			if t.pos >= len(t.data) {
+				return "", false, fmt.Errorf("line %d: unterminated quoted token", line)
+			}
+			c := t.data[t.pos]
+			t.pos++
+			if c == '\n' {
+				t.line++
+			}
+			switch c {
+			case '"':
+				return b.String(), true, nil
+			case '\\':
+				if t.pos < len(t.data) {
+					if t.data[t.pos] == '\n' {
+						t.line++
+					}
+					b.WriteByte(t.data[t.pos])
+					t.pos++
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+	}
+
+	for t.pos < len(t.data) {
+		c := t.data[t.pos]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v' {
+			break
+		}
+		if c == '\\' && t.pos+1 < len(t.data) && t.data[t.pos+1] != '\n' {
+			t.pos++
+			c = t.data[t.pos]
+		}
+		b.WriteByte(c)
+		t.pos++
+	}
+	return b.String(), true, nil
+}
+
+// value returns the token following keyword.
+func (t *tokenizer) value(keyword string) (string, error) {
+	value, ok, err := t.next()
+	if err != nil {
+		return "", err
+	} else if !ok {
+		return "", fmt.Errorf("line %d: missing value for %q", t.line, keyword)
+	}
+	return value, nil
+}
+
+// skipMacro skips the body of a macro definition, which extends from the end
+// of the current line to the next empty line. Lines ending in "\r\n" are
+// empty if nothing precedes the "\r".
+func (t *tokenizer) skipMacro() {
+	for {
+		end := strings.IndexByte(t.data[t.pos:], '\n')
+		if end < 0 {
+			t.pos = len(t.data)
+			return
+		}
+		t.pos += end + 1
+		t.line++
+
+		line, _, _ := strings.Cut(t.data[t.pos:], "\n")
+		if strings.TrimSuffix(line, "\r") == "" {
+			return
+		}
+	}
+}
+
+// New returns a [credentialhelper.CredentialHelper] returning a `Basic`
+// `Authorization` header with the login and password of the entry of netrc
+// matching the host of the requested URI.
+//
+// Requests for hosts without entry fail with an error wrapping
+// [credentialhelper.ErrNoCredentials].
+func New(netrc *Netrc) credentialhelper.CredentialHelper {
+	return &netrcCredentialHelper{netrc: netrc}
+}
+
+type netrcCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	netrc *Netrc
+}
+
+func (h *netrcCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	host, err := credentialhelperrouter.HostFromURI(request.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	machine := h.netrc.Lookup(host)
+	if machine == nil {
+		return nil, fmt.Errorf("%w: no .netrc entry for host %q", credentialhelper.ErrNoCredentials, host)
+	}
+
+	token := base64.StdEncoding.EncodeToString([]byte(machine.Login + ":" + machine.Password))
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {"Basic " + token},
+		},
+	}, nil
+}
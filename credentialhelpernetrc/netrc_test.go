@@ -0,0 +1,164 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelpernetrc_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelpernetrc"
+)
+
+const testNetrc = `# A comment.
+machine example.com login alice password secret
+
+machine "quoted.example.com"
+  login "bob smith"
+  password "pa\"ss word"
+  account acct
+
+macdef init
+machine evil.example.com login mallory password macro
+cd /pub
+
+machine EXAMPLE.org login carol password "a#b" # trailing comment
+machine example.com login shadowed password shadowed
+default login anonymous password guest@example.com
+`
+
+func TestParse(t *testing.T) {
+	netrc, err := credentialhelpernetrc.Parse(strings.NewReader(testNetrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &credentialhelpernetrc.Netrc{
+		Machines: []credentialhelpernetrc.Machine{
+			{Name: "example.com", Login: "alice", Password: "secret"},
+			{Name: "quoted.example.com", Login: "bob smith", Password: `pa"ss word`, Account: "acct"},
+			{Name: "example.org", Login: "carol", Password: "a#b"},
+			{Name: "example.com", Login: "shadowed", Password: "shadowed"},
+		},
+		Default: &credentialhelpernetrc.Machine{Login: "anonymous", Password: "guest@example.com"},
+	}
+	if diff := cmp.Diff(want, netrc); diff != "" {
+		t.Errorf("unexpected netrc (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseCRLF(t *testing.T) {
+	netrc, err := credentialhelpernetrc.Parse(strings.NewReader("macdef init\r\ncd /\r\n\r\nmachine a.com login u password p\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &credentialhelpernetrc.Netrc{
+		Machines: []credentialhelpernetrc.Machine{
+			{Name: "a.com", Login: "u", Password: "p"},
+		},
+	}
+	if diff := cmp.Diff(want, netrc); diff != "" {
+		t.Errorf("unexpected netrc (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, content := range []string{
+		"login alice",
+		"machine",
+		"machine example.com login",
+		`machine "example.com`,
+		"machine example.com user alice",
+	} {
+		if _, err := credentialhelpernetrc.Parse(strings.NewReader(content)); err == nil {
+			t.Errorf("expected error for %q", content)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".netrc")
+	if err := os.WriteFile(path, []byte("machine example.com login alice password secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	netrc, err := credentialhelpernetrc.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if machine := netrc.Lookup("EXAMPLE.com"); machine == nil || machine.Login != "alice" {
+		t.Errorf("unexpected entry for example.com: %v", machine)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(credentialhelpernetrc.PathEnvironmentVariable, "/custom/netrc")
+
+	path, err := credentialhelpernetrc.DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/custom/netrc" {
+		t.Errorf("expected path from %s, got %q", credentialhelpernetrc.PathEnvironmentVariable, path)
+	}
+}
+
+func basic(login string, password string) []string {
+	return []string{"Basic " + base64.StdEncoding.EncodeToString([]byte(login+":"+password))}
+}
+
+func TestNetrcCredentialHelper(t *testing.T) {
+	netrc, err := credentialhelpernetrc.Parse(strings.NewReader(testNetrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper := credentialhelpernetrc.New(netrc)
+
+	for uri, want := range map[string][]string{
+		"https://example.com/path":     basic("alice", "secret"),
+		"https://Example.org:8443":     basic("carol", "a#b"),
+		"https://quoted.example.com":   basic("bob smith", `pa"ss word`),
+		"https://evil.example.com":     basic("anonymous", "guest@example.com"),
+		"https://unknown.example.test": basic("anonymous", "guest@example.com"),
+	} {
+		response, err := helper.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: uri})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string][]string{"Authorization": want}, response.Headers); diff != "" {
+			t.Errorf("unexpected headers for %q (-want +got):\n%s", uri, diff)
+		}
+	}
+}
+
+func TestNetrcCredentialHelperNoMatch(t *testing.T) {
+	netrc, err := credentialhelpernetrc.Parse(strings.NewReader("machine example.com login alice password secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = credentialhelpernetrc.New(netrc).GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.org"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+}
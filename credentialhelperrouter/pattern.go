@@ -0,0 +1,111 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperrouter
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Pattern selects hosts by name.
+//
+// Use [ParsePattern] to create an instance.
+type Pattern struct {
+	host     string
+	wildcard bool
+}
+
+// ParsePattern parses a pattern selecting hosts, using the same syntax as
+// the scope of Bazel's `--credential_helper` flag:
+//
+//   - `example.com` matches exactly the host `example.com`,
+//   - `*.example.com` matches `example.com` and all its subdomains (e.g.,
+//     `foo.example.com` and `foo.bar.example.com`).
+//
+// Patterns are case-insensitive. Internationalized domain names must be given
+// in their ASCII (Punycode) form.
+func ParsePattern(pattern string) (Pattern, error) {
+	host, wildcard := strings.CutPrefix(pattern, "*.")
+	host = strings.ToLower(host)
+
+	if !wildcard && net.ParseIP(host) != nil {
+		return Pattern{host: host}, nil
+	}
+	if !isValidDomain(host) {
+		return Pattern{}, fmt.Errorf("invalid host pattern %q", pattern)
+	}
+	return Pattern{host: host, wildcard: wildcard}, nil
+}
+
+// Matches returns whether host is selected by the pattern. The host must be
+// normalized as done by [HostFromURI].
+func (p Pattern) Matches(host string) bool {
+	if host == p.host {
+		return true
+	}
+	return p.wildcard && strings.HasSuffix(host, "."+p.host)
+}
+
+func (p Pattern) String() string {
+	if p.wildcard {
+		return "*." + p.host
+	}
+	return p.host
+}
+
+// HostFromURI returns the host of uri, lower-cased and without port, as
+// matched against patterns.
+func HostFromURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return "", fmt.Errorf("URI %q has no host", uri)
+	}
+	return strings.ToLower(host), nil
+}
+
+// parentDomain returns the parent domain of domain (e.g., `example.com` for
+// `foo.example.com`), or false if it has none.
+func parentDomain(domain string) (string, bool) {
+	i := strings.IndexByte(domain, '.')
+	if i < 0 {
+		return "", false
+	}
+	return domain[i+1:], true
+}
+
+// isValidDomain returns whether domain is a syntactically valid domain name.
+func isValidDomain(domain string) bool {
+	if domain == "" || len(domain) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !('a' <= c && c <= 'z') && !('0' <= c && c <= '9') && c != '-' && c != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
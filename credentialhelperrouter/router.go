@@ -0,0 +1,112 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialhelperrouter provides a [credentialhelper.CredentialHelper]
+// dispatching requests to other credential helpers based on the host of the
+// requested URI, following the same rules as Bazel's `--credential_helper`
+// flag.
+package credentialhelperrouter
+
+import (
+	"context"
+	"fmt"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+)
+
+// Route maps requests for URIs whose host matches Pattern to Helper.
+type Route struct {
+	// Pattern selects the hosts served by Helper. See [ParsePattern] for the
+	// syntax. An empty Pattern makes Helper the default for all hosts not
+	// matched by any other route.
+	Pattern string
+
+	// Helper is the credential helper serving requests matching Pattern.
+	Helper credentialhelper.CredentialHelper
+}
+
+// New returns a [credentialhelper.CredentialHelper] dispatching requests to
+// the helper of the most specific route matching the host of the requested
+// URI:
+//
+//  1. a route for exactly the host,
+//  2. a wildcard route for the closest parent domain of the host (e.g.,
+//     `*.foo.example.com` before `*.example.com` for `bar.foo.example.com`),
+//  3. the default route.
+//
+// If several routes have the same pattern, the last one wins, like repeated
+// `--credential_helper` flags in Bazel. Requests not matching any route fail
+// with an error wrapping [credentialhelper.ErrNoCredentials].
+func New(routes []Route) (credentialhelper.CredentialHelper, error) {
+	r := &router{
+		exact:    make(map[string]credentialhelper.CredentialHelper),
+		wildcard: make(map[string]credentialhelper.CredentialHelper),
+	}
+	for _, route := range routes {
+		if route.Helper == nil {
+			return nil, fmt.Errorf("route for %q has no helper", route.Pattern)
+		}
+
+		if route.Pattern == "" {
+			r.defaultHelper = route.Helper
+			continue
+		}
+
+		pattern, err := ParsePattern(route.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if pattern.wildcard {
+			r.wildcard[pattern.host] = route.Helper
+		} else {
+			r.exact[pattern.host] = route.Helper
+		}
+	}
+	return r, nil
+}
+
+type router struct {
+	credentialhelper.CredentialHelperBase
+
+	exact         map[string]credentialhelper.CredentialHelper
+	wildcard      map[string]credentialhelper.CredentialHelper
+	defaultHelper credentialhelper.CredentialHelper
+}
+
+// GetCredentials forwards the request to the helper selected by the host of
+// the requested URI.
+func (r *router) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	host, err := HostFromURI(request.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	helper := r.lookup(host)
+	if helper == nil {
+		return nil, fmt.Errorf("%w: no credential helper configured for host %q", credentialhelper.ErrNoCredentials, host)
+	}
+	return helper.GetCredentials(ctx, request, extraParameters...)
+}
+
+func (r *router) lookup(host string) credentialhelper.CredentialHelper {
+	if helper, ok := r.exact[host]; ok {
+		return helper
+	}
+	for domain, ok := host, true; ok; domain, ok = parentDomain(domain) {
+		if helper, ok := r.wildcard[domain]; ok {
+			return helper
+		}
+	}
+	return r.defaultHelper
+}
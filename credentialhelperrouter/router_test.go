@@ -0,0 +1,183 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelperrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	credentialhelper "github.com/EngFlow/credential-helper-go"
+	"github.com/EngFlow/credential-helper-go/credentialhelperrouter"
+)
+
+// namedCredentialHelper returns its name and the extra parameters it was
+// called with as headers.
+type namedCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+
+	name string
+}
+
+func (h *namedCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"helper": {h.name},
+			"extra":  extraParameters,
+		},
+	}, nil
+}
+
+func route(pattern string) credentialhelperrouter.Route {
+	name := pattern
+	if name == "" {
+		name = "default"
+	}
+	return credentialhelperrouter.Route{
+		Pattern: pattern,
+		Helper:  &namedCredentialHelper{name: name},
+	}
+}
+
+func TestRouter(t *testing.T) {
+	router, err := credentialhelperrouter.New([]credentialhelperrouter.Route{
+		route(""),
+		route("example.com"),
+		route("*.example.com"),
+		route("*.foo.example.com"),
+		route("bar.foo.example.com"),
+		route("10.0.0.1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		uri  string
+		want string
+	}{
+		{uri: "https://example.com/path", want: "example.com"},
+		{uri: "https://EXAMPLE.com:8443", want: "example.com"},
+		{uri: "https://baz.example.com", want: "*.example.com"},
+		{uri: "https://foo.example.com", want: "*.foo.example.com"},
+		{uri: "grpcs://qux.foo.example.com", want: "*.foo.example.com"},
+		{uri: "https://bar.foo.example.com", want: "bar.foo.example.com"},
+		{uri: "https://baz.bar.foo.example.com", want: "*.foo.example.com"},
+		{uri: "https://10.0.0.1:8080", want: "10.0.0.1"},
+		{uri: "https://example.org", want: "default"},
+		{uri: "https://notexample.com", want: "default"},
+	} {
+		t.Run(tc.uri, func(t *testing.T) {
+			response, err := router.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: tc.uri})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]string{tc.want}, response.Headers["helper"]); diff != "" {
+				t.Errorf("unexpected helper (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRouterLaterRoutesOverride(t *testing.T) {
+	router, err := credentialhelperrouter.New([]credentialhelperrouter.Route{
+		{Pattern: "*.example.com", Helper: &namedCredentialHelper{name: "first"}},
+		{Pattern: "*.EXAMPLE.com", Helper: &namedCredentialHelper{name: "second"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := router.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://foo.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"second"}, response.Headers["helper"]); diff != "" {
+		t.Errorf("unexpected helper (-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterForwardsExtraParameters(t *testing.T) {
+	router, err := credentialhelperrouter.New([]credentialhelperrouter.Route{route("")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := router.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.com"}, "--foo=bar", "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"--foo=bar", "baz"}, response.Headers["extra"]); diff != "" {
+		t.Errorf("unexpected extra parameters (-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterNoMatch(t *testing.T) {
+	router, err := credentialhelperrouter.New([]credentialhelperrouter.Route{route("example.com")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = router.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "https://example.org"})
+	if !errors.Is(err, credentialhelper.ErrNoCredentials) {
+		t.Errorf("expected error wrapping ErrNoCredentials, got %v", err)
+	}
+
+	_, err = router.GetCredentials(context.Background(), &credentialhelper.GetCredentialsRequest{URI: "not a uri"})
+	if err == nil {
+		t.Error("expected error for URI without host")
+	}
+}
+
+func TestNewRejectsInvalidRoutes(t *testing.T) {
+	for _, routes := range [][]credentialhelperrouter.Route{
+		{route("*.")},
+		{route("*")},
+		{route("foo..example.com")},
+		{route("-foo.example.com")},
+		{route("foo.example.com/path")},
+		{route("*.10.0.0.1:8080")},
+		{{Pattern: "example.com"}},
+	} {
+		if _, err := credentialhelperrouter.New(routes); err == nil {
+			t.Errorf("expected error for pattern %q", routes[0].Pattern)
+		}
+	}
+}
+
+func TestPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{pattern: "example.com", host: "example.com", want: true},
+		{pattern: "example.com", host: "foo.example.com", want: false},
+		{pattern: "*.example.com", host: "example.com", want: true},
+		{pattern: "*.example.com", host: "foo.bar.example.com", want: true},
+		{pattern: "*.example.com", host: "fooexample.com", want: false},
+		{pattern: "*.Example.COM", host: "foo.example.com", want: true},
+	} {
+		pattern, err := credentialhelperrouter.ParsePattern(tc.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pattern.Matches(tc.host); got != tc.want {
+			t.Errorf("ParsePattern(%q).Matches(%q) = %v, want %v", tc.pattern, tc.host, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+const doctorCommand = "doctor"
+
+// runDoctorCommand fetches credentials for the URI in args[0] from helper,
+// and prints a report about the response with all secrets redacted.
+func runDoctorCommand(ctx context.Context, timeout time.Duration, stdout io.Writer, stderr io.Writer, args []string, helper CredentialHelper) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "Missing URI for command '"+doctorCommand+"'")
+		return 1
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	request := &GetCredentialsRequest{
+		URI: args[0],
+	}
+	fmt.Fprintf(stdout, "Fetching credentials for %s\n", request.URI)
+
+	start := time.Now()
+	response, err := helper.GetCredentials(ctx, request, args[1:]...)
+	elapsed := time.Since(start)
+	if err != nil {
+		fmt.Fprintf(stdout, "FAILED after %v: %v\n", elapsed.Round(time.Millisecond), err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Received response after %v\n", elapsed.Round(time.Millisecond))
+
+	redacted := redactResponse(response)
+	fmt.Fprintln(stdout, "")
+	fmt.Fprintln(stdout, "Headers:")
+	if len(redacted.Headers) == 0 {
+		fmt.Fprintln(stdout, "  (none)")
+	}
+	names := make([]string, 0, len(redacted.Headers))
+	for name := range redacted.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range redacted.Headers[name] {
+			fmt.Fprintf(stdout, "  %s: %s\n", name, value)
+		}
+	}
+
+	fmt.Fprintln(stdout, "")
+	if response.Expires == nil {
+		fmt.Fprintln(stdout, "Expires: (not set)")
+	} else {
+		fmt.Fprintf(stdout, "Expires: %s (in %v)\n", response.Expires.Format(time.RFC3339), time.Until(*response.Expires).Round(time.Second))
+	}
+
+	// Print what the helper would write for `get`, to catch errors in
+	// marshaling the response.
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		fmt.Fprintf(stdout, "FAILED to marshal response: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(stdout, "")
+	fmt.Fprintln(stdout, "Response (redacted):")
+	fmt.Fprintf(stdout, "  %s\n", data)
+
+	fmt.Fprintln(stdout, "")
+	problems := responseProblems(response, time.Now())
+	if len(problems) == 0 {
+		fmt.Fprintln(stdout, "OK")
+		return 0
+	}
+	fmt.Fprintf(stdout, "FAILED with %d problem(s):\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(stdout, "  - %v\n", problem)
+	}
+	return 1
+}
+
+// redactResponse returns a copy of response with all header values replaced
+// by a placeholder describing them.
+func redactResponse(response *GetCredentialsResponse) *GetCredentialsResponse {
+	redacted := &GetCredentialsResponse{
+		Expires: response.Expires,
+	}
+	if response.Headers != nil {
+		redacted.Headers = make(map[string][]string, len(response.Headers))
+	}
+	for name, values := range response.Headers {
+		redactedValues := make([]string, len(values))
+		for i, value := range values {
+			redactedValues[i] = redactValue(value)
+		}
+		redacted.Headers[name] = redactedValues
+	}
+	return redacted
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticCredentialHelper always returns the same response.
+type staticCredentialHelper struct {
+	CredentialHelperBase
+
+	response *GetCredentialsResponse
+}
+
+func (h staticCredentialHelper) GetCredentials(ctx context.Context, request *GetCredentialsRequest, extraParameters ...string) (*GetCredentialsResponse, error) {
+	return h.response, nil
+}
+
+func TestDoctor_OK(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	exitCode, stdout, _ := runHelper(
+		context.Background(),
+		staticCredentialHelper{
+			response: &GetCredentialsResponse{
+				Headers: map[string][]string{
+					"Authorization": {"Bearer secret-token"},
+				},
+				Expires: &expires,
+			},
+		},
+		"",
+		"doctor", "https://example.com/foo")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "Fetching credentials for https://example.com/foo\n")
+	assert.Contains(t, stdout, "  Authorization: [redacted, 19 bytes, sha256:")
+	assert.Contains(t, stdout, `{"headers":{"Authorization":["[redacted, 19 bytes, sha256:`)
+	assert.Contains(t, stdout, "\nOK\n")
+	assert.NotContains(t, stdout, "secret-token")
+}
+
+func TestDoctor_Problems(t *testing.T) {
+	expires := time.Now().Add(-time.Hour)
+	exitCode, stdout, _ := runHelper(
+		context.Background(),
+		staticCredentialHelper{
+			response: &GetCredentialsResponse{
+				Headers: map[string][]string{
+					"Bad Name": {"value"},
+					"Empty":    {},
+					"Injected": {"secret\r\nX-Evil: 1"},
+				},
+				Expires: &expires,
+			},
+		},
+		"",
+		"doctor", "https://example.com/foo")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout, "FAILED with 4 problem(s):\n")
+	assert.Contains(t, stdout, `header "Bad Name": name is not a valid token`)
+	assert.Contains(t, stdout, `header "Empty": no values`)
+	assert.Contains(t, stdout, `header "Injected": value 0 contains control character '\r' at offset 6`)
+	assert.Contains(t, stdout, "credentials expired at")
+	assert.NotContains(t, stdout, "secret")
+}
+
+func TestDoctor_MissingURI(t *testing.T) {
+	exitCode, _, stderr := runHelper(
+		context.Background(),
+		staticCredentialHelper{},
+		"",
+		"doctor")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Missing URI")
+}
@@ -0,0 +1,268 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors reported by credential helpers.
+//
+// Implementations of [CredentialHelper] can wrap these errors to signal why
+// they could not provide credentials. [StartCredentialHelper] reports them
+// with a distinct exit code and a machine-readable line on stderr, and
+// clients created with [NewClient] turn them back into a [*ProtocolError]
+// wrapping the same error.
+var (
+	// ErrNoCredentials indicates that no credentials are available for the
+	// requested URI.
+	ErrNoCredentials = errors.New("no credentials available")
+
+	// ErrLoginRequired indicates that the user must log in interactively
+	// before credentials are available.
+	ErrLoginRequired = errors.New("login required")
+
+	// ErrTransient indicates a temporary failure (e.g., of the network);
+	// retrying later might succeed.
+	ErrTransient = errors.New("transient failure")
+)
+
+// protocolErrorKinds maps the errors reported by credential helpers to their
+// names on the wire and their exit codes.
+var protocolErrorKinds = []struct {
+	err      error
+	name     string
+	exitCode int
+}{
+	{ErrNoCredentials, "no-credentials", ExitCodeNoCredentials},
+	{ErrLoginRequired, "login-required", ExitCodeLoginRequired},
+	{ErrTransient, "transient", ExitCodeTransient},
+}
+
+// protocolErrorPrefix starts the line on stderr describing an error reported
+// by a credential helper.
+const protocolErrorPrefix = "credential-helper-error: "
+
+// protocolErrorLine is the machine-readable description of an error reported
+// by a credential helper.
+type protocolErrorLine struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// ProtocolError is an error a credential helper reported in a
+// machine-readable way.
+//
+// It wraps one of [ErrNoCredentials], [ErrLoginRequired] or [ErrTransient],
+// so use [errors.Is] to check what kind of error it is.
+type ProtocolError struct {
+	// Kind is the kind of the error.
+	Kind error
+
+	// Message is the message of the error as reported by the credential
+	// helper.
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Message
+}
+
+func (e *ProtocolError) Unwrap() error {
+	return e.Kind
+}
+
+// formatProtocolError returns the line describing err on stderr, and the
+// exit code to use for it. It returns false if err is not one of the errors
+// reported by credential helpers.
+func formatProtocolError(err error) (string, int, bool) {
+	for _, kind := range protocolErrorKinds {
+		if errors.Is(err, kind.err) {
+			line, marshalErr := json.Marshal(protocolErrorLine{
+				Kind:    kind.name,
+				Message: err.Error(),
+			})
+			if marshalErr != nil {
+				return "", 0, false
+			}
+			return protocolErrorPrefix + string(line), kind.exitCode, true
+		}
+	}
+	return "", 0, false
+}
+
+// parseProtocolError returns the last error described on stderr of a
+// credential helper, or nil if there is none.
+func parseProtocolError(stderr []byte) *ProtocolError {
+	var result *ProtocolError
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		text, ok := strings.CutPrefix(scanner.Text(), protocolErrorPrefix)
+		if !ok {
+			continue
+		}
+
+		var line protocolErrorLine
+		if err := json.Unmarshal([]byte(text), &line); err != nil {
+			continue
+		}
+		for _, kind := range protocolErrorKinds {
+			if kind.name == line.Kind {
+				result = &ProtocolError{
+					Kind:    kind.err,
+					Message: line.Message,
+				}
+			}
+		}
+	}
+	return result
+}
+
+// ErrResponseTooLarge is wrapped by the [*HelperError] returned when a
+// credential helper writes a response exceeding
+// [ClientOptions.MaxStdoutSize].
+var ErrResponseTooLarge = errors.New("response of credential helper is too large")
+
+// HelperErrorPhase identifies the stage at which invoking a credential helper
+// failed.
+type HelperErrorPhase int
+
+const (
+	// PhaseLookup indicates that the credential helper executable could not be
+	// found.
+	PhaseLookup HelperErrorPhase = iota + 1
+
+	// PhaseStart indicates that the credential helper process could not be
+	// started.
+	PhaseStart
+
+	// PhaseExit indicates that the credential helper exited unsuccessfully.
+	PhaseExit
+
+	// PhaseTimeout indicates that the credential helper did not finish before
+	// its deadline.
+	PhaseTimeout
+
+	// PhaseCanceled indicates that the invocation was canceled before the
+	// credential helper finished.
+	PhaseCanceled
+
+	// PhaseResponse indicates that the credential helper finished successfully
+	// but its response could not be read.
+	PhaseResponse
+)
+
+func (p HelperErrorPhase) String() string {
+	switch p {
+	case PhaseLookup:
+		return "lookup"
+	case PhaseStart:
+		return "start"
+	case PhaseExit:
+		return "exit"
+	case PhaseTimeout:
+		return "timeout"
+	case PhaseCanceled:
+		return "canceled"
+	case PhaseResponse:
+		return "response"
+	default:
+		return fmt.Sprintf("HelperErrorPhase(%d)", int(p))
+	}
+}
+
+// HelperError is returned when invoking a credential helper fails.
+//
+// Use [errors.As] to inspect the details of the failure.
+type HelperError struct {
+	// Path is the path of the credential helper executable.
+	Path string
+
+	// Command is the subcommand the credential helper was invoked with (e.g.,
+	// `get`). Empty if the failure happened before invoking a command.
+	Command string
+
+	// Phase is the stage at which the invocation failed.
+	Phase HelperErrorPhase
+
+	// ExitCode is the exit code of the credential helper, or -1 if the
+	// process did not exit on its own (or was never started).
+	ExitCode int
+
+	// Stderr contains what the credential helper wrote to stderr.
+	Stderr []byte
+
+	// StderrTruncated is true if the credential helper wrote more to stderr
+	// than [ClientOptions.MaxStderrSize], in which case Stderr only contains
+	// the end of it.
+	StderrTruncated bool
+
+	// Elapsed is the time spent running the credential helper.
+	Elapsed time.Duration
+
+	// Err is the underlying error.
+	Err error
+
+	// Protocol is the error reported by the credential helper, if it
+	// described it in a machine-readable way.
+	Protocol *ProtocolError
+}
+
+func (e *HelperError) Error() string {
+	switch e.Phase {
+	case PhaseLookup:
+		return fmt.Sprintf("could not lookup credential helper %q: %v", e.Path, e.Err)
+	case PhaseStart:
+		return fmt.Sprintf("could not start credential helper %q: %v", e.Path, e.Err)
+	case PhaseTimeout:
+		return fmt.Sprintf("error running credential helper %q: timed out after %v: %v", e.Path, e.Elapsed.Round(time.Millisecond), e.Err)
+	case PhaseResponse:
+		return fmt.Sprintf("could not read response from credential helper %q: %v", e.Path, e.Err)
+	default:
+		if e.Protocol != nil {
+			return fmt.Sprintf("error running credential helper %q: %v: %v", e.Path, e.Err, e.Protocol)
+		}
+		return fmt.Sprintf("error running credential helper %q: %v", e.Path, e.Err)
+	}
+}
+
+// Unwrap returns Err and Protocol, if set. For [PhaseTimeout] and
+// [PhaseCanceled], it also returns the error of the context that ended the
+// invocation, so that [errors.Is] matches [context.DeadlineExceeded] and
+// [context.Canceled] respectively.
+func (e *HelperError) Unwrap() []error {
+	errs := []error{e.Err}
+	if e.Protocol != nil {
+		errs = append(errs, e.Protocol)
+	}
+	switch e.Phase {
+	case PhaseTimeout:
+		if !errors.Is(e.Err, context.DeadlineExceeded) {
+			errs = append(errs, context.DeadlineExceeded)
+		}
+	case PhaseCanceled:
+		if !errors.Is(e.Err, context.Canceled) {
+			errs = append(errs, context.Canceled)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// unknownFields returns the fields of the JSON object in data other than
+// known, or nil if there are none.
+//
+// Like [json.Unmarshal], known fields are matched case-insensitively.
+func unknownFields(data []byte, known ...string) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var extensions map[string]json.RawMessage
+	for name, value := range fields {
+		if isKnownField(name, known) {
+			continue
+		}
+		if extensions == nil {
+			extensions = make(map[string]json.RawMessage)
+		}
+		extensions[name] = value
+	}
+	return extensions, nil
+}
+
+// appendFields adds extensions to the non-empty JSON object in data, sorted by
+// name. Extensions named like one of known are skipped.
+func appendFields(data []byte, extensions map[string]json.RawMessage, known ...string) ([]byte, error) {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		if !isKnownField(name, known) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return data, nil
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(bytes.TrimSpace(data), []byte("}")))
+	for _, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value := extensions[name]
+		if len(value) == 0 {
+			value = json.RawMessage("null")
+		}
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func isKnownField(name string, known []string) bool {
+	for _, k := range known {
+		if strings.EqualFold(name, k) {
+			return true
+		}
+	}
+	return false
+}
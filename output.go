@@ -0,0 +1,67 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+// headBuffer is an [io.Writer] keeping the first `limit` bytes written to it.
+//
+// Writes never fail so that the writing process is not blocked; instead,
+// `exceeded` records whether anything was discarded.
+type headBuffer struct {
+	limit    int
+	data     []byte
+	exceeded bool
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if remaining := b.limit - len(b.data); n > remaining {
+		p = p[:remaining]
+		b.exceeded = true
+	}
+	b.data = append(b.data, p...)
+	return n, nil
+}
+
+func (b *headBuffer) Bytes() []byte {
+	return b.data
+}
+
+// tailBuffer is an [io.Writer] keeping the last `limit` bytes written to it.
+//
+// `truncated` records whether anything was discarded.
+type tailBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= b.limit {
+		b.truncated = b.truncated || n > b.limit || len(b.data) > 0
+		b.data = append(b.data[:0], p[n-b.limit:]...)
+		return n, nil
+	}
+	if overflow := len(b.data) + n - b.limit; overflow > 0 {
+		b.data = append(b.data[:0], b.data[overflow:]...)
+		b.truncated = true
+	}
+	b.data = append(b.data, p...)
+	return n, nil
+}
+
+func (b *tailBuffer) Bytes() []byte {
+	return b.data
+}
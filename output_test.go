@@ -0,0 +1,55 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadBuffer(t *testing.T) {
+	b := &headBuffer{limit: 5}
+
+	n, err := b.Write([]byte("abc"))
+	assert.Equal(t, 3, n)
+	assert.NoError(t, err)
+	assert.False(t, b.exceeded)
+
+	n, err = b.Write([]byte("defg"))
+	assert.Equal(t, 4, n)
+	assert.NoError(t, err)
+	assert.True(t, b.exceeded)
+	assert.Equal(t, "abcde", string(b.Bytes()))
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{limit: 5}
+
+	b.Write([]byte("abc"))
+	assert.Equal(t, "abc", string(b.Bytes()))
+	assert.False(t, b.truncated)
+
+	b.Write([]byte("de"))
+	assert.Equal(t, "abcde", string(b.Bytes()))
+	assert.False(t, b.truncated)
+
+	b.Write([]byte("fg"))
+	assert.Equal(t, "cdefg", string(b.Bytes()))
+	assert.True(t, b.truncated)
+
+	b.Write([]byte("0123456789"))
+	assert.Equal(t, "56789", string(b.Bytes()))
+}
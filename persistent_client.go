@@ -0,0 +1,484 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// serveHandshakeTimeout specifies how long to wait for a credential helper to
+// announce that it supports the `serve` command.
+var serveHandshakeTimeout = 5 * time.Second
+
+// serveEnvelopeSize is the allowance for the JSON framing of a
+// [serveResponse] on top of the output it carries.
+const serveEnvelopeSize = 4 << 10
+
+// serveLineLimit returns the maximum size of a line written by a credential
+// helper running the `serve` command. Every line carries the output of one
+// request, with stderr escaped as JSON string, which takes up to twice its
+// size for common control characters such as newlines.
+func serveLineLimit(options ClientOptions) int {
+	return options.MaxStdoutSize + 2*options.MaxStderrSize + serveEnvelopeSize
+}
+
+// errServeUnsupported is returned by startServeProcess if the credential
+// helper does not support the `serve` command.
+var errServeUnsupported = errors.New("credential helper does not support command '" + serveCommand + "'")
+
+// PersistentClient represents a [CredentialHelper] that keeps the
+// credential helper running between requests.
+//
+// Use [NewPersistentClient] to create an instance.
+type PersistentClient interface {
+	CredentialHelper
+
+	// Close stops the credential helper and releases all associated
+	// resources.
+	Close() error
+}
+
+// NewPersistentClient is like [NewClientWithOptions], but starts the
+// credential helper once with the `serve` command and sends it all requests
+// over stdin and stdout, instead of spawning a new process for every request.
+//
+// Credential helpers implemented using [StartCredentialHelper] support the
+// `serve` command. If the credential helper crashes, it is restarted for the
+// next request. If it does not support the `serve` command, requests fall back
+// to invoking it once per request.
+//
+// To find out whether the `serve` command is supported, the first request
+// runs the credential helper with the `serve` command and an empty stdin
+// before starting it for good. This costs one additional process, but
+// credential helpers not supporting the `serve` command see the end of their
+// input right away, so they fail or respond quickly instead of blocking
+// until the handshake times out. Only credential helpers neither reading
+// stdin nor exiting delay the first request by up to 5 seconds.
+//
+// [ClientOptions.MaxStdoutSize] and [ClientOptions.MaxStderrSize] apply to
+// the output of each request. A credential helper writing a response larger
+// than these limits allow for is treated as crashed.
+func NewPersistentClient(credentialHelperPath string, options ClientOptions) (PersistentClient, error) {
+	helper, err := NewClientWithOptions(credentialHelperPath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &persistentClient{
+		client: helper.(*client),
+	}
+	return c, nil
+}
+
+type persistentClient struct {
+	CredentialHelperBase
+
+	client *client
+
+	mutex       sync.Mutex
+	closed      bool
+	probed      bool
+	unsupported bool
+	process     *serveProcess
+}
+
+// GetCredentials sends a request to fetch credentials to the running
+// credential helper, starting it if necessary.
+func (c *persistentClient) GetCredentials(ctx context.Context, request *GetCredentialsRequest, extraParameters ...string) (*GetCredentialsResponse, error) {
+	process, err := c.getProcess()
+	if err != nil {
+		return nil, err
+	}
+	if process == nil {
+		return c.client.GetCredentials(ctx, request, extraParameters...)
+	}
+
+	stdin, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.client.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.client.options.Timeout)
+		defer cancel()
+	}
+
+	helperErr := func(phase HelperErrorPhase, exitCode int, stderr *tailBuffer, elapsed time.Duration, err error) *HelperError {
+		e := &HelperError{
+			Path:     c.client.credentialHelperPath,
+			Command:  "get",
+			Phase:    phase,
+			ExitCode: exitCode,
+			Elapsed:  elapsed,
+			Err:      err,
+		}
+		if stderr != nil {
+			e.Stderr = stderr.Bytes()
+			e.StderrTruncated = stderr.truncated
+		}
+		return e
+	}
+
+	start := time.Now()
+	result, err := process.call(ctx, append([]string{"get"}, extraParameters...), stdin)
+	if err != nil {
+		if _, ok := err.(*HelperError); ok {
+			return nil, err
+		}
+
+		phase := PhaseCanceled
+		if errors.Is(err, context.DeadlineExceeded) {
+			phase = PhaseTimeout
+		}
+		return nil, helperErr(phase, -1, nil, time.Since(start), err)
+	}
+
+	stderr := &tailBuffer{limit: c.client.options.MaxStderrSize}
+	stderr.Write([]byte(result.Stderr))
+
+	if result.ExitCode != 0 {
+		e := helperErr(PhaseExit, result.ExitCode, stderr, time.Since(start), fmt.Errorf("exit status %d", result.ExitCode))
+		e.Protocol = parseProtocolError(e.Stderr)
+		return nil, e
+	}
+
+	if len(result.Stdout) > c.client.options.MaxStdoutSize {
+		return nil, helperErr(PhaseResponse, 0, stderr, time.Since(start), fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, c.client.options.MaxStdoutSize))
+	}
+
+	response, err := UnmarshalGetCredentialsResponse(result.Stdout, c.client.options.ExpiryParsing)
+	if err != nil {
+		return nil, helperErr(PhaseResponse, 0, stderr, time.Since(start), err)
+	}
+	if err := c.client.validate(response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// getProcess returns the running credential helper, starting it if
+// necessary, or nil if requests should fall back to the regular client.
+func (c *persistentClient) getProcess() (*serveProcess, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil, errors.New("cannot get credentials from closed credential helper")
+	}
+	if c.unsupported {
+		return nil, nil
+	}
+	if c.process != nil && !c.process.exited() {
+		return c.process, nil
+	}
+
+	if !c.probed {
+		err := probeServe(c.client)
+		if errors.Is(err, errServeUnsupported) {
+			c.unsupported = true
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		c.probed = true
+	}
+
+	process, err := startServeProcess(c.client)
+	if errors.Is(err, errServeUnsupported) {
+		c.unsupported = true
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	c.process = process
+	return process, nil
+}
+
+func (c *persistentClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		// Already closed.
+		return nil
+	}
+	c.closed = true
+
+	if c.process != nil {
+		c.process.stop(c.client.options.CancelGracePeriod)
+	}
+	return nil
+}
+
+// probeServe runs the credential helper with the `serve` command and an empty
+// stdin, and returns errServeUnsupported unless it starts with the handshake.
+func probeServe(c *client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), serveHandshakeTimeout)
+	defer cancel()
+
+	stdout, _, err := c.run(ctx, serveCommand, nil, []string{serveCommand})
+	var helperErr *HelperError
+	if errors.As(err, &helperErr) && helperErr.Phase == PhaseStart {
+		return err
+	}
+
+	line, _, _ := bytes.Cut(stdout, []byte("\n"))
+	var hello serveHello
+	if err := json.Unmarshal(line, &hello); err != nil || hello.Version != serveProtocolVersion {
+		return errServeUnsupported
+	}
+	return nil
+}
+
+// serveProcess represents a credential helper running the `serve` command.
+type serveProcess struct {
+	client *client
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr *tailBuffer
+
+	writeMutex sync.Mutex
+	encoder    *json.Encoder
+
+	mutex   sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan *serveResponse
+
+	// done is closed once the process exited and its output was read
+	// completely. waitErr is the result of waiting for it, and readErr the
+	// reason its output could not be read, if any.
+	done    chan struct{}
+	waitErr error
+	readErr error
+}
+
+func startServeProcess(c *client) (*serveProcess, error) {
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderr := &tailBuffer{limit: c.options.MaxStderrSize}
+
+	// The process outlives any single request, so it is never canceled by a
+	// context; stop and kill take care of terminating it.
+	cmd := exec.CommandContext(context.Background(), c.credentialHelperPath, append(append([]string{}, c.options.Args...), serveCommand)...)
+	cmd.Dir = c.options.WorkingDirectory
+	cmd.Env = c.environ()
+	cmd.WaitDelay = c.options.CancelGracePeriod
+	setupProcessGroup(cmd)
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, &HelperError{
+			Path:     c.credentialHelperPath,
+			Command:  serveCommand,
+			Phase:    PhaseStart,
+			ExitCode: -1,
+			Err:      err,
+		}
+	}
+
+	p := &serveProcess{
+		client:  c,
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		encoder: json.NewEncoder(stdin),
+		pending: make(map[uint64]chan *serveResponse),
+		done:    make(chan struct{}),
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		p.waitErr = cmd.Wait()
+		close(exited)
+		stdoutWriter.Close()
+	}()
+
+	scanner := bufio.NewScanner(stdoutReader)
+	scanner.Buffer(nil, serveLineLimit(c.options))
+
+	handshake := make(chan error, 1)
+	go func() {
+		if !scanner.Scan() {
+			handshake <- errServeUnsupported
+			return
+		}
+		var hello serveHello
+		if err := json.Unmarshal(scanner.Bytes(), &hello); err != nil || hello.Version != serveProtocolVersion {
+			handshake <- errServeUnsupported
+			return
+		}
+		handshake <- nil
+	}()
+
+	select {
+	case err = <-handshake:
+	case <-time.After(serveHandshakeTimeout):
+		err = errServeUnsupported
+	}
+	if err != nil {
+		p.kill(exited)
+		stdoutReader.Close()
+		<-exited
+		return nil, err
+	}
+
+	go func() {
+		p.readErr = p.readResponses(scanner)
+		// Ensure the process does not linger if its output is unusable.
+		p.kill(exited)
+		stdoutReader.Close()
+		<-exited
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// readResponses dispatches responses to pending requests until the output of
+// the process ends or is invalid, in which case it returns why.
+func (p *serveProcess) readResponses(scanner *bufio.Scanner) error {
+	for scanner.Scan() {
+		var response serveResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+
+		p.mutex.Lock()
+		if ch, ok := p.pending[response.ID]; ok {
+			ch <- &response
+			delete(p.pending, response.ID)
+		}
+		p.mutex.Unlock()
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return fmt.Errorf("%w: limit is %d bytes per line", ErrResponseTooLarge, serveLineLimit(p.client.options))
+	}
+	return scanner.Err()
+}
+
+// call sends a request to the credential helper and waits for its response.
+func (p *serveProcess) call(ctx context.Context, args []string, stdin []byte) (*serveResponse, error) {
+	ch := make(chan *serveResponse, 1)
+
+	p.mutex.Lock()
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = ch
+	p.mutex.Unlock()
+
+	defer func() {
+		p.mutex.Lock()
+		delete(p.pending, id)
+		p.mutex.Unlock()
+	}()
+
+	p.writeMutex.Lock()
+	err := p.encoder.Encode(&serveRequest{
+		ID:    id,
+		Args:  args,
+		Stdin: stdin,
+	})
+	p.writeMutex.Unlock()
+	if err != nil {
+		<-p.done
+		return nil, p.exitError()
+	}
+
+	select {
+	case response := <-ch:
+		return response, nil
+	case <-p.done:
+		// The response might have arrived right before the process exited.
+		select {
+		case response := <-ch:
+			return response, nil
+		default:
+			return nil, p.exitError()
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// exitError returns the error for requests which were not answered because
+// the process exited. It must only be called once done is closed.
+func (p *serveProcess) exitError() error {
+	err := p.waitErr
+	if p.readErr != nil {
+		// The process was killed because of its output.
+		err = p.readErr
+	} else if err == nil {
+		err = errors.New("credential helper exited unexpectedly")
+	}
+	return &HelperError{
+		Path:            p.client.credentialHelperPath,
+		Command:         serveCommand,
+		Phase:           PhaseExit,
+		ExitCode:        p.cmd.ProcessState.ExitCode(),
+		Stderr:          p.stderr.Bytes(),
+		StderrTruncated: p.stderr.truncated,
+		Err:             err,
+	}
+}
+
+func (p *serveProcess) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// kill kills the process unless it already exited.
+func (p *serveProcess) kill(exited <-chan struct{}) {
+	select {
+	case <-exited:
+	default:
+		killProcessGroup(p.cmd)
+		p.cmd.Process.Kill()
+	}
+}
+
+// stop asks the process to exit by closing its stdin, and kills it if it
+// does not do so within gracePeriod.
+func (p *serveProcess) stop(gracePeriod time.Duration) {
+	p.writeMutex.Lock()
+	p.stdin.Close()
+	p.writeMutex.Unlock()
+
+	select {
+	case <-p.done:
+	case <-time.After(gracePeriod):
+		killProcessGroup(p.cmd)
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || linux
+
+package credentialhelper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EngFlow/credential-helper-go"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentClient_ReusesProcess(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	response1, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response2, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/bar",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"https://example.com/foo"}, response1.Headers["uri"])
+	assert.Equal(t, []string{"https://example.com/bar"}, response2.Headers["uri"])
+	assert.Equal(t, response1.Headers["pid"], response2.Headers["pid"])
+}
+
+func TestPersistentClient_Concurrent(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			uri := fmt.Sprintf("https://example.com/%d", i)
+			response, err := client.GetCredentials(
+				context.Background(),
+				&credentialhelper.GetCredentialsRequest{
+					URI: uri,
+				})
+			if assert.NoError(t, err) {
+				assert.Equal(t, []string{uri}, response.Headers["uri"])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPersistentClient_RestartsAfterCrash(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	response1, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: CrashURI,
+		})
+	assert.Nil(t, response)
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseExit, helperErr.Phase)
+		assert.Equal(t, 42, helperErr.ExitCode)
+	}
+
+	response2, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotEqual(t, response1.Headers["pid"], response2.Headers["pid"])
+}
+
+func TestPersistentClient_FallsBackIfUnsupported(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient("testdata/with-headers.sh", credentialhelper.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		response, err := client.GetCredentials(
+			context.Background(),
+			&credentialhelper.GetCredentialsRequest{
+				URI: "https://example.com/foo",
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(
+			t,
+			&credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{
+					"foo": {"bar", "baz"},
+					"bar": {"hello", "world"},
+				},
+			},
+			response)
+	}
+}
+
+func TestPersistentClient_FallsBackQuicklyIfReadingStdin(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient("testdata/read-stdin.sh", credentialhelper.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, &credentialhelper.GetCredentialsResponse{}, response)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPersistentClient_ResponseTooLarge(t *testing.T) {
+	options := testHelperOptions()
+	options.MaxStdoutSize = 1024
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	response1, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: LargeResponseURI,
+		})
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, credentialhelper.ErrResponseTooLarge)
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseResponse, helperErr.Phase)
+	}
+
+	// The credential helper keeps serving other requests.
+	response2, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/bar",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, response1.Headers["pid"], response2.Headers["pid"])
+}
+
+func TestPersistentClient_LineTooLarge(t *testing.T) {
+	options := testHelperOptions()
+	options.MaxStdoutSize = 1024
+	options.MaxStderrSize = 1024
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	response1, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The line carrying the response exceeds what any valid response
+	// takes, so the credential helper is treated as crashed.
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: LargeResponseURI,
+		})
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, credentialhelper.ErrResponseTooLarge)
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Equal(t, credentialhelper.PhaseExit, helperErr.Phase)
+	}
+
+	response2, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/bar",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotEqual(t, response1.Headers["pid"], response2.Headers["pid"])
+}
+
+func TestPersistentClient_Closed(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		})
+	assert.Error(t, err)
+	assert.Nil(t, response)
+}
@@ -15,50 +15,182 @@
 package credentialhelper
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// TimeoutEnvironmentVariable specifies the deadline for requests to a
+	// credential helper started with [StartCredentialHelper], formatted as
+	// accepted by [time.ParseDuration]. The `--timeout` option takes
+	// precedence over it.
+	TimeoutEnvironmentVariable = "CREDENTIAL_HELPER_TIMEOUT"
+
+	// ExitCodeNoCredentials is the exit code of a credential helper started
+	// with [StartCredentialHelper] if the helper returned an error wrapping
+	// [ErrNoCredentials].
+	ExitCodeNoCredentials = 3
+
+	// ExitCodeLoginRequired is the exit code of a credential helper started
+	// with [StartCredentialHelper] if the helper returned an error wrapping
+	// [ErrLoginRequired].
+	ExitCodeLoginRequired = 4
+
+	// ExitCodeTransient is the exit code of a credential helper started with
+	// [StartCredentialHelper] if the helper returned an error wrapping
+	// [ErrTransient].
+	ExitCodeTransient = 5
+
+	// ExitCodeTimeout is the exit code of a credential helper started with
+	// [StartCredentialHelper] or [RunMain] if a request exceeded its deadline.
+	ExitCodeTimeout = 124
+
+	// ExitCodeCanceled is the exit code of a credential helper started with
+	// [StartCredentialHelper] or [RunMain] if it was interrupted by a signal.
+	ExitCodeCanceled = 130
 )
 
 // StartCredentialHelper is a util for turning the current process as credential helper.
 //
+// The context passed to the helper is canceled when the process receives
+// SIGINT or SIGTERM. A deadline for each request can be set with the
+// `--timeout=<duration>` option before the command (e.g.,
+// `helper --timeout=10s get`) or with [TimeoutEnvironmentVariable].
+//
+// Additional commands (e.g., `login`) can be provided in commands. They are
+// listed in the output of `help` alongside the commands of the Helper
+// Protocol. It panics if commands are defined more than once.
+//
 // This function never returns.
-func StartCredentialHelper(helper CredentialHelper) {
-	os.Exit(startCredentialHelper(os.Stdin, os.Stdout, os.Stderr, os.Args, helper))
+func StartCredentialHelper(helper CredentialHelper, commands ...*Command) {
+	// The timeout applies to each request rather than the whole process, so
+	// it is handled by startCredentialHelper.
+	RunMain(0, func(ctx context.Context) int {
+		return startCredentialHelper(ctx, os.Stdin, os.Stdout, os.Stderr, os.Args, helper, commands...)
+	})
 }
 
-func startCredentialHelper(stdin io.ReadCloser, stdout io.WriteCloser, stderr io.WriteCloser, args []string, helper CredentialHelper) int {
-	if len(args) < 2 {
-		printHelp(stderr, args[0])
-		return 1
+// RunMain runs main as the body of the current process and exits the process
+// with the exit code main returns. It is meant for the entry points of
+// programs serving credentials in other protocols, which should parse the
+// timeout with [ParseTimeout].
+//
+// The context passed to main is canceled when the process receives SIGINT or
+// SIGTERM. Signals are only caught until then, so a second one terminates the
+// process right away. If timeout is not 0, the context additionally expires
+// after timeout. If main fails after the context is done, the process exits
+// with [ExitCodeTimeout] or [ExitCodeCanceled].
+//
+// This function never returns.
+func RunMain(timeout time.Duration, main func(ctx context.Context) int) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	exitCode := runMain(ctx, timeout, main)
+	stop()
+	os.Exit(exitCode)
+}
+
+func runMain(ctx context.Context, timeout time.Duration, main func(ctx context.Context) int) int {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	switch args[1] {
-	case "get":
-		return runGetCommand(stdin, stdout, stderr, args, helper)
+	exitCode := main(ctx)
+	if exitCode != 0 && ctx.Err() != nil {
+		return exitCodeForContext(ctx)
+	}
+	return exitCode
+}
 
-	default:
-		fmt.Fprintln(stderr, "Unknown command '"+args[1]+"'")
-		fmt.Fprintln(stderr, "")
-		printHelp(stderr, args[0])
+func startCredentialHelper(ctx context.Context, stdin io.Reader, stdout io.Writer, stderr io.Writer, args []string, helper CredentialHelper, commands ...*Command) int {
+	timeout, args, err := ParseTimeout(args)
+	if err != nil {
+		message := err.Error()
+		fmt.Fprintln(stderr, strings.ToUpper(message[:1])+message[1:])
 		return 1
 	}
+
+	protocolCommands := []*Command{
+		{
+			Name:    "get",
+			Summary: "Prints credentials for the request read from stdin",
+			Usage:   "[extra parameters]",
+			Run: func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+				return runGetCommand(ctx, timeout, stdin, stdout, stderr, args, helper)
+			},
+		},
+		{
+			Name:    serveCommand,
+			Summary: "Answers requests read from stdin until it is closed",
+			Run: func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+				return runServeCommand(ctx, timeout, stdin, stdout, stderr, args, helper)
+			},
+		},
+	}
+	builtinCommands := []*Command{
+		{
+			Name:    doctorCommand,
+			Summary: "Checks the credentials for a URI and prints a redacted report",
+			Usage:   "<uri> [extra parameters]",
+			Run: func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+				return runDoctorCommand(ctx, timeout, stdout, stderr, args, helper)
+			},
+		},
+	}
+
+	return newCommandSet(args[0], protocolCommands, builtinCommands, commands).run(ctx, stdin, stdout, stderr, args[1:])
 }
 
-func printHelp(w io.Writer, procName string) {
-	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  "+path.Base(procName)+" <command>")
+// ParseTimeout extracts the `--timeout=<duration>` option preceding the
+// command from args, which start with the name of the program, falling back to
+// [TimeoutEnvironmentVariable]. It returns the timeout and args without the
+// option. A timeout of 0 means no deadline.
+func ParseTimeout(args []string) (time.Duration, []string, error) {
+	value := os.Getenv(TimeoutEnvironmentVariable)
+	source := TimeoutEnvironmentVariable
+	for len(args) >= 2 && strings.HasPrefix(args[1], "--timeout=") {
+		value = strings.TrimPrefix(args[1], "--timeout=")
+		source = "--timeout"
+		args = append([]string{args[0]}, args[2:]...)
+	}
+	if value == "" {
+		return 0, args, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid value for %s: %v", source, err)
+	} else if timeout < 0 {
+		return 0, nil, fmt.Errorf("invalid value for %s: must not be negative, got %v", source, timeout)
+	}
+	return timeout, args, nil
 }
 
-func runGetCommand(stdin io.ReadCloser, stdout io.WriteCloser, stderr io.WriteCloser, args []string, helper CredentialHelper) int {
-	if len(args) != 2 {
-		printHelp(stderr, args[0])
-		return 1
+// exitCodeForContext returns the exit code for a request that failed while
+// ctx was done.
+func exitCodeForContext(ctx context.Context) int {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ExitCodeTimeout
 	}
+	return ExitCodeCanceled
+}
 
+func runGetCommand(ctx context.Context, timeout time.Duration, stdin io.Reader, stdout io.Writer, stderr io.Writer, args []string, helper CredentialHelper) int {
 	var request GetCredentialsRequest
 	if err := json.NewDecoder(stdin).Decode(&request); err != nil {
 		fmt.Fprintln(stderr, "Invalid request for command 'get':")
@@ -66,9 +198,22 @@ func runGetCommand(stdin io.ReadCloser, stdout io.WriteCloser, stderr io.WriteCl
 		return 1
 	}
 
-	response, err := helper.GetCredentials(context.Background(), &request)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	response, err := helper.GetCredentials(ctx, &request, args...)
 	if err != nil {
 		fmt.Fprintln(stderr, err.Error())
+		if ctx.Err() != nil {
+			return exitCodeForContext(ctx)
+		}
+		if line, exitCode, ok := formatProtocolError(err); ok {
+			fmt.Fprintln(stderr, line)
+			return exitCode
+		}
 		return 1
 	}
 
@@ -79,3 +224,141 @@ func runGetCommand(stdin io.ReadCloser, stdout io.WriteCloser, stderr io.WriteCl
 
 	return 0
 }
+
+// ParseExtraParameters splits the extra parameters passed to
+// [CredentialHelper.GetCredentials] into options and positional arguments.
+//
+// Options are parameters of the form `--name=value`, or `--name` which is
+// equivalent to `--name=true`. A single leading dash is accepted as well. All
+// parameters after `--` are positional arguments.
+func ParseExtraParameters(extraParameters []string) (options map[string]string, args []string) {
+	options = make(map[string]string)
+	for i, parameter := range extraParameters {
+		if parameter == "--" {
+			args = append(args, extraParameters[i+1:]...)
+			break
+		}
+		if len(parameter) < 2 || parameter[0] != '-' {
+			args = append(args, parameter)
+			continue
+		}
+
+		name := strings.TrimPrefix(parameter[1:], "-")
+		value := "true"
+		if j := strings.IndexByte(name, '='); j >= 0 {
+			name, value = name[:j], name[j+1:]
+		}
+		options[name] = value
+	}
+	return options, args
+}
+
+func runServeCommand(ctx context.Context, timeout time.Duration, stdin io.Reader, stdout io.Writer, stderr io.Writer, args []string, helper CredentialHelper) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "Command '"+serveCommand+"' does not take arguments")
+		return 1
+	}
+
+	var writeMutex sync.Mutex
+	encoder := json.NewEncoder(stdout)
+	write := func(message any) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+
+		return encoder.Encode(message)
+	}
+
+	if err := write(serveHello{Version: serveProtocolVersion}); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// Requests are read in the background so that the loop below can stop
+	// as soon as the context is canceled.
+	type decodeResult struct {
+		request *serveRequest
+		err     error
+	}
+	requests := make(chan decodeResult)
+	go func() {
+		decoder := json.NewDecoder(stdin)
+		for {
+			var request serveRequest
+			err := decoder.Decode(&request)
+			select {
+			case requests <- decodeResult{&request, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var result decodeResult
+		select {
+		case result = <-requests:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			wg.Wait()
+			return exitCodeForContext(ctx)
+		}
+
+		if result.err == io.EOF {
+			return 0
+		} else if result.err != nil {
+			fmt.Fprintln(stderr, "Invalid request for command '"+serveCommand+"':")
+			fmt.Fprintln(stderr, result.err.Error())
+			return 1
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			response := serveRequestLocally(ctx, timeout, result.request, helper)
+			if err := write(response); err != nil {
+				fmt.Fprintln(stderr, err.Error())
+			}
+		}()
+	}
+}
+
+// serveRequestLocally runs the invocation described by request in the
+// current process.
+func serveRequestLocally(ctx context.Context, timeout time.Duration, request *serveRequest, helper CredentialHelper) *serveResponse {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	exitCode := 1
+	if len(request.Args) == 0 {
+		fmt.Fprintln(&stderr, "Missing command for command '"+serveCommand+"'")
+	} else if request.Args[0] != "get" {
+		fmt.Fprintln(&stderr, "Unsupported command '"+request.Args[0]+"' for command '"+serveCommand+"'")
+	} else {
+		exitCode = runGetCommand(
+			ctx,
+			timeout,
+			bytes.NewReader(request.Stdin),
+			&stdout,
+			&stderr,
+			request.Args[1:],
+			helper)
+	}
+
+	response := &serveResponse{
+		ID:       request.ID,
+		ExitCode: exitCode,
+		Stderr:   stderr.String(),
+	}
+	if exitCode == 0 {
+		response.Stdout = stdout.Bytes()
+	}
+	return response
+}
@@ -0,0 +1,313 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingCredentialHelper blocks until the context is done.
+type blockingCredentialHelper struct {
+	CredentialHelperBase
+}
+
+func (blockingCredentialHelper) GetCredentials(ctx context.Context, request *GetCredentialsRequest, extraParameters ...string) (*GetCredentialsResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func runHelper(ctx context.Context, helper CredentialHelper, stdin string, args ...string) (int, string, string) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	exitCode := startCredentialHelper(
+		ctx,
+		strings.NewReader(stdin),
+		&stdout,
+		&stderr,
+		append([]string{"/path/to/helper"}, args...),
+		helper)
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func TestStartCredentialHelper_TimeoutOption(t *testing.T) {
+	exitCode, _, stderr := runHelper(
+		context.Background(),
+		blockingCredentialHelper{},
+		`{"uri": "https://example.com"}`,
+		"--timeout=10ms", "get")
+	assert.Equal(t, ExitCodeTimeout, exitCode)
+	assert.Contains(t, stderr, "deadline exceeded")
+}
+
+func TestStartCredentialHelper_TimeoutEnvironmentVariable(t *testing.T) {
+	t.Setenv(TimeoutEnvironmentVariable, "10ms")
+
+	exitCode, _, _ := runHelper(
+		context.Background(),
+		blockingCredentialHelper{},
+		`{"uri": "https://example.com"}`,
+		"get")
+	assert.Equal(t, ExitCodeTimeout, exitCode)
+}
+
+func TestStartCredentialHelper_InvalidTimeout(t *testing.T) {
+	exitCode, _, stderr := runHelper(
+		context.Background(),
+		blockingCredentialHelper{},
+		`{"uri": "https://example.com"}`,
+		"--timeout=soon", "get")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Invalid value for --timeout")
+}
+
+func TestStartCredentialHelper_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	exitCode, _, _ := runHelper(
+		ctx,
+		blockingCredentialHelper{},
+		`{"uri": "https://example.com"}`,
+		"get")
+	assert.Equal(t, ExitCodeCanceled, exitCode)
+}
+
+func TestStartCredentialHelper_ServeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Requests are never answered, as the helper blocks until canceled.
+	exitCode, _, _ := runHelper(
+		ctx,
+		blockingCredentialHelper{},
+		`{"id": 1, "args": ["get"], "stdin": {"uri": "https://example.com"}}`,
+		"serve")
+	assert.Equal(t, ExitCodeCanceled, exitCode)
+}
+
+func TestRunMain(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context
+		timeout  time.Duration
+		exitCode int
+		want     int
+	}{
+		{"success", context.Background(), 0, 0, 0},
+		{"failure", context.Background(), 0, 1, 1},
+		{"timeout", context.Background(), time.Nanosecond, 1, ExitCodeTimeout},
+		{"success despite timeout", context.Background(), time.Nanosecond, 0, 0},
+		{"canceled", canceled, 0, 1, ExitCodeCanceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			exitCode := runMain(tc.ctx, tc.timeout, func(ctx context.Context) int {
+				if tc.timeout > 0 {
+					<-ctx.Done()
+				}
+				return tc.exitCode
+			})
+			assert.Equal(t, tc.want, exitCode)
+		})
+	}
+}
+
+func versionCommand() *Command {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	short := flags.Bool("short", false, "Only print the version number")
+	return &Command{
+		Name:    "version",
+		Summary: "Prints the version of the helper",
+		Usage:   "[flags]",
+		Flags:   flags,
+		Run: func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+			if *short {
+				fmt.Fprintln(stdout, "1.2.3")
+			} else {
+				fmt.Fprintln(stdout, "helper version 1.2.3", args)
+			}
+			return 0
+		},
+	}
+}
+
+func TestStartCredentialHelper_Command(t *testing.T) {
+	var stdout bytes.Buffer
+	exitCode := startCredentialHelper(
+		context.Background(),
+		strings.NewReader(""),
+		&stdout,
+		io.Discard,
+		[]string{"/path/to/helper", "version", "--short"},
+		blockingCredentialHelper{},
+		versionCommand())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "1.2.3\n", stdout.String())
+
+	stdout.Reset()
+	exitCode = startCredentialHelper(
+		context.Background(),
+		strings.NewReader(""),
+		&stdout,
+		io.Discard,
+		[]string{"/path/to/helper", "version", "foo"},
+		blockingCredentialHelper{},
+		versionCommand())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "helper version 1.2.3 [foo]\n", stdout.String())
+}
+
+func TestStartCredentialHelper_Help(t *testing.T) {
+	var stdout bytes.Buffer
+	exitCode := startCredentialHelper(
+		context.Background(),
+		strings.NewReader(""),
+		&stdout,
+		io.Discard,
+		[]string{"/path/to/helper", "--help"},
+		blockingCredentialHelper{},
+		versionCommand())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(
+		t,
+		`Usage:
+  helper [--timeout=<duration>] <command> [arguments]
+
+Commands:
+  get      Prints credentials for the request read from stdin
+  serve    Answers requests read from stdin until it is closed
+  doctor   Checks the credentials for a URI and prints a redacted report
+  version  Prints the version of the helper
+  help     Prints help about a command
+
+Run 'helper help <command>' for more information about a command.
+`,
+		stdout.String())
+}
+
+func TestStartCredentialHelper_CommandHelp(t *testing.T) {
+	var stdout bytes.Buffer
+	exitCode := startCredentialHelper(
+		context.Background(),
+		strings.NewReader(""),
+		&stdout,
+		io.Discard,
+		[]string{"/path/to/helper", "help", "version"},
+		blockingCredentialHelper{},
+		versionCommand())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(
+		t,
+		`Usage:
+  helper version [flags]
+
+Prints the version of the helper
+
+Flags:
+  -short
+    	Only print the version number
+`,
+		stdout.String())
+
+	var stderr bytes.Buffer
+	exitCode = startCredentialHelper(
+		context.Background(),
+		strings.NewReader(""),
+		io.Discard,
+		&stderr,
+		[]string{"/path/to/helper", "version", "--help"},
+		blockingCredentialHelper{},
+		versionCommand())
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, stdout.String(), stderr.String())
+}
+
+func TestStartCredentialHelper_UnknownCommand(t *testing.T) {
+	exitCode, _, stderr := runHelper(context.Background(), blockingCredentialHelper{}, "", "foo")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Unknown command 'foo'")
+	assert.Contains(t, stderr, "Commands:")
+}
+
+func TestStartCredentialHelper_DuplicateCommand(t *testing.T) {
+	assert.Panics(t, func() {
+		startCredentialHelper(
+			context.Background(),
+			strings.NewReader(""),
+			io.Discard,
+			io.Discard,
+			[]string{"/path/to/helper", "get"},
+			blockingCredentialHelper{},
+			&Command{
+				Name: "get",
+				Run: func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+					return 0
+				},
+			})
+	})
+}
+
+// failingCredentialHelper fails with the given error.
+type failingCredentialHelper struct {
+	CredentialHelperBase
+
+	err error
+}
+
+func (h failingCredentialHelper) GetCredentials(ctx context.Context, request *GetCredentialsRequest, extraParameters ...string) (*GetCredentialsResponse, error) {
+	return nil, h.err
+}
+
+func TestStartCredentialHelper_ProtocolErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		exitCode int
+	}{
+		{fmt.Errorf("%w for example.com", ErrNoCredentials), ExitCodeNoCredentials},
+		{fmt.Errorf("%w: run `helper login`", ErrLoginRequired), ExitCodeLoginRequired},
+		{fmt.Errorf("%w: connection reset", ErrTransient), ExitCodeTransient},
+		{errors.New("something else"), 1},
+	} {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			exitCode, _, stderr := runHelper(
+				context.Background(),
+				failingCredentialHelper{err: tc.err},
+				`{"uri": "https://example.com"}`,
+				"get")
+			assert.Equal(t, tc.exitCode, exitCode)
+			assert.True(t, strings.HasPrefix(stderr, tc.err.Error()+"\n"))
+
+			protocolErr := parseProtocolError([]byte(stderr))
+			if tc.exitCode == 1 {
+				assert.Nil(t, protocolErr)
+			} else if assert.NotNil(t, protocolErr) {
+				assert.ErrorIs(t, protocolErr, errors.Unwrap(tc.err))
+				assert.Equal(t, tc.err.Error(), protocolErr.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/EngFlow/credential-helper-go"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// TestHelperEnvironmentVariable makes the test binary act as a credential
+	// helper built with `StartCredentialHelper`.
+	TestHelperEnvironmentVariable = "CREDENTIAL_HELPER_TEST_HELPER"
+
+	// CrashURI makes the test credential helper exit without responding.
+	CrashURI = "https://crash.example.com/"
+
+	// LoginRequiredURI makes the test credential helper report
+	// `ErrLoginRequired`.
+	LoginRequiredURI = "https://login.example.com/"
+
+	// LargeResponseURI makes the test credential helper respond with a
+	// header of 8192 bytes.
+	LargeResponseURI = "https://large.example.com/"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(TestHelperEnvironmentVariable) != "" {
+		credentialhelper.StartCredentialHelper(&testCredentialHelper{})
+	}
+
+	os.Exit(m.Run())
+}
+
+type testCredentialHelper struct {
+	credentialhelper.CredentialHelperBase
+}
+
+func (h *testCredentialHelper) GetCredentials(ctx context.Context, request *credentialhelper.GetCredentialsRequest, extraParameters ...string) (*credentialhelper.GetCredentialsResponse, error) {
+	switch request.URI {
+	case CrashURI:
+		os.Exit(42)
+	case LoginRequiredURI:
+		return nil, fmt.Errorf("%w: run `helper login`", credentialhelper.ErrLoginRequired)
+	case LargeResponseURI:
+		return &credentialhelper.GetCredentialsResponse{
+			Headers: map[string][]string{
+				"large": {strings.Repeat("x", 8192)},
+			},
+		}, nil
+	}
+
+	return &credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"uri":  {request.URI},
+			"pid":  {fmt.Sprint(os.Getpid())},
+			"args": extraParameters,
+		},
+	}, nil
+}
+
+// testHelperOptions returns options for invoking the test binary as a
+// credential helper.
+func testHelperOptions() credentialhelper.ClientOptions {
+	return credentialhelper.ClientOptions{
+		Env: map[string]string{
+			TestHelperEnvironmentVariable: "1",
+		},
+	}
+}
+
+func testHelperPath(t *testing.T) string {
+	path, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStartCredentialHelper_ExtraParameters(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		},
+		"--flag=value", "positional")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--flag=value", "positional"}, response.Headers["args"])
+}
+
+func TestStartCredentialHelper_ExtraParametersPersistent(t *testing.T) {
+	client, err := credentialhelper.NewPersistentClient(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	response, err := client.GetCredentials(
+		context.Background(),
+		&credentialhelper.GetCredentialsRequest{
+			URI: "https://example.com/foo",
+		},
+		"--flag=value", "positional")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--flag=value", "positional"}, response.Headers["args"])
+}
+
+func TestParseExtraParameters(t *testing.T) {
+	options, args := credentialhelper.ParseExtraParameters(
+		[]string{"--foo=bar", "first", "-baz", "--empty=", "-", "--", "--not-a-flag"})
+	assert.Equal(
+		t,
+		map[string]string{
+			"foo":   "bar",
+			"baz":   "true",
+			"empty": "",
+		},
+		options)
+	assert.Equal(t, []string{"first", "-", "--not-a-flag"}, args)
+}
+
+func TestStartCredentialHelper_ProtocolError(t *testing.T) {
+	client, err := credentialhelper.NewClientWithOptions(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	persistentClient, err := credentialhelper.NewPersistentClient(testHelperPath(t), testHelperOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer persistentClient.Close()
+
+	for _, helper := range []credentialhelper.CredentialHelper{client, persistentClient} {
+		response, err := helper.GetCredentials(
+			context.Background(),
+			&credentialhelper.GetCredentialsRequest{
+				URI: LoginRequiredURI,
+			})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, credentialhelper.ErrLoginRequired)
+		assert.NotErrorIs(t, err, credentialhelper.ErrNoCredentials)
+		assert.ErrorContains(t, err, "login required: run `helper login`")
+
+		var protocolErr *credentialhelper.ProtocolError
+		if assert.True(t, errors.As(err, &protocolErr)) {
+			assert.Equal(t, "login required: run `helper login`", protocolErr.Message)
+		}
+
+		var helperErr *credentialhelper.HelperError
+		if assert.True(t, errors.As(err, &helperErr)) {
+			assert.Equal(t, credentialhelper.ExitCodeLoginRequired, helperErr.ExitCode)
+		}
+	}
+}
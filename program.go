@@ -0,0 +1,60 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"context"
+)
+
+// Program represents an external program invoked with the same safeguards as
+// credential helpers: timeouts, process group cleanup, and limits on the size
+// of its output. It is useful for adapters to other credential protocols
+// (e.g., Docker credential helpers).
+//
+// Use [NewProgram] to create an instance.
+type Program struct {
+	client *client
+}
+
+// NewProgram returns a [Program] running the program at path, which is looked
+// up like [NewClientWithOptions] does, with options. [ClientOptions.Strict]
+// and [ClientOptions.ExpiryParsing] have no effect.
+func NewProgram(path string, options ClientOptions) (*Program, error) {
+	helper, err := NewClientWithOptions(path, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{client: helper.(*client)}, nil
+}
+
+// Path returns the absolute path of the program.
+func (p *Program) Path() string {
+	return p.client.credentialHelperPath
+}
+
+// Run runs the program with args following [ClientOptions.Args], writing
+// stdin to its standard input, and returns its standard output.
+//
+// Errors are [*HelperError]s whose Command is the first of args. If the
+// program exits with a non-zero exit code, its standard output is returned
+// along with the error, as some protocols report errors there.
+func (p *Program) Run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	stdout, _, err := p.client.run(ctx, command, stdin, args)
+	return stdout, err
+}
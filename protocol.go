@@ -15,14 +15,170 @@
 package credentialhelper
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// ExpiryParsing specifies how the `expires` field of a
+// [GetCredentialsResponse] is parsed.
+type ExpiryParsing int
+
+const (
+	// ExpiryParsingStrict accepts RFC 3339 timestamps with an uppercase `T`
+	// separator, seconds, optional fractional seconds, and either an
+	// uppercase `Z` or a numeric `±hh:mm` offset (e.g.,
+	// `2006-01-02T15:04:05.123Z`), as emitted by [time.Time.MarshalJSON].
+	//
+	// This is the default.
+	ExpiryParsingStrict ExpiryParsing = iota
+
+	// ExpiryParsingLenient additionally accepts timestamps with a lowercase
+	// `t` or `z`, a space instead of `T`, or an offset without colon (e.g.,
+	// `+0100`), as well as Unix epoch seconds given as JSON number or string.
+	//
+	// This is useful when wrapping third-party tools; credential helpers used
+	// by Bazel should not rely on it.
+	ExpiryParsingLenient
+)
+
+// strictExpiresPattern matches the timestamps accepted by
+// [ExpiryParsingStrict].
+var strictExpiresPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d{1,9})?(Z|[+-]\d{2}:\d{2})$`)
+
+// epochSecondsPattern matches the Unix epoch seconds accepted by
+// [ExpiryParsingLenient].
+var epochSecondsPattern = regexp.MustCompile(`^\d+(\.\d{1,9})?$`)
+
+// lenientExpiresLayouts are the layouts tried by [ExpiryParsingLenient] after
+// normalizing the separator and `Z` to uppercase.
+var lenientExpiresLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+// parseExpires parses the raw value of the `expires` field of a response.
+func parseExpires(raw json.RawMessage, mode ExpiryParsing) (*time.Time, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if raw[0] != '"' {
+		if mode != ExpiryParsingLenient {
+			return nil, fmt.Errorf("expires must be a string, got %s", raw)
+		}
+		return parseEpochSeconds(string(raw))
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case ExpiryParsingStrict:
+		if !strictExpiresPattern.MatchString(value) {
+			return nil, fmt.Errorf("expires %q is not a valid RFC 3339 timestamp", value)
+		}
+		expires, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("expires %q is not a valid RFC 3339 timestamp: %w", value, err)
+		}
+		return &expires, nil
+
+	case ExpiryParsingLenient:
+		if expires, err := parseEpochSeconds(value); err == nil {
+			return expires, nil
+		}
+
+		normalized := value
+		if len(normalized) > 10 && (normalized[10] == 't' || normalized[10] == ' ') {
+			normalized = normalized[:10] + "T" + normalized[11:]
+		}
+		if strings.HasSuffix(normalized, "z") {
+			normalized = strings.TrimSuffix(normalized, "z") + "Z"
+		}
+		for _, layout := range lenientExpiresLayouts {
+			if expires, err := time.Parse(layout, normalized); err == nil {
+				return &expires, nil
+			}
+		}
+		return nil, fmt.Errorf("expires %q is neither a timestamp nor Unix epoch seconds", value)
+
+	default:
+		return nil, fmt.Errorf("unknown expiry parsing mode %d", mode)
+	}
+}
+
+// parseEpochSeconds parses s as (possibly fractional) seconds since the Unix
+// epoch.
+func parseEpochSeconds(s string) (*time.Time, error) {
+	if !epochSecondsPattern.MatchString(s) {
+		return nil, fmt.Errorf("expires %s is not a valid Unix epoch timestamp", s)
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	seconds, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expires %s is not a valid Unix epoch timestamp: %w", s, err)
+	}
+	var nanos int64
+	if fraction != "" {
+		// The pattern guarantees at most 9 digits.
+		nanos, _ = strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+	}
+
+	expires := time.Unix(seconds, nanos)
+	return &expires, nil
+}
+
 // GetCredentialsRequest represents the request for the `get` command of the Helper Protocol.
 type GetCredentialsRequest struct {
 	// The URI to get credentials for.
 	URI string `json:"uri"`
+
+	// Extensions holds fields not defined by the Helper Protocol, keyed by
+	// their name. They are preserved when parsing and added back when
+	// marshaling, so that requests can be forwarded to other credential
+	// helpers without losing information.
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+func (req *GetCredentialsRequest) UnmarshalJSON(data []byte) error {
+	var v struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	extensions, err := unknownFields(data, "uri")
+	if err != nil {
+		return err
+	}
+
+	*req = GetCredentialsRequest{
+		URI:        v.URI,
+		Extensions: extensions,
+	}
+	return nil
+}
+
+func (req GetCredentialsRequest) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		URI string `json:"uri"`
+	}{
+		URI: req.URI,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return appendFields(data, req.Extensions, "uri")
 }
 
 // GetCredentialsResponse represents the response for the `get` command of the Helper Protocol.
@@ -33,6 +189,55 @@ type GetCredentialsResponse struct {
 	// The time the credentials expire and stop being valid for new requests,
 	// formatted following [RFC 3339](https://www.rfc-editor.org/rfc/rfc3339.html).
 	Expires *time.Time `json:"expires"`
+
+	// Extensions holds fields not defined by the Helper Protocol, keyed by
+	// their name. They are preserved when parsing and added back when
+	// marshaling.
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalGetCredentialsResponse parses a [GetCredentialsResponse] from
+// data, parsing the `expires` field according to mode.
+func UnmarshalGetCredentialsResponse(data []byte, mode ExpiryParsing) (*GetCredentialsResponse, error) {
+	var resp GetCredentialsResponse
+	if err := resp.unmarshalJSON(data, mode); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] using [ExpiryParsingStrict].
+// Use [UnmarshalGetCredentialsResponse] to parse the `expires` field
+// leniently.
+func (resp *GetCredentialsResponse) UnmarshalJSON(data []byte) error {
+	return resp.unmarshalJSON(data, ExpiryParsingStrict)
+}
+
+func (resp *GetCredentialsResponse) unmarshalJSON(data []byte, mode ExpiryParsing) error {
+	var v struct {
+		Headers map[string][]string `json:"headers"`
+		Expires json.RawMessage     `json:"expires"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	expires, err := parseExpires(v.Expires, mode)
+	if err != nil {
+		return err
+	}
+
+	extensions, err := unknownFields(data, "headers", "expires")
+	if err != nil {
+		return err
+	}
+
+	*resp = GetCredentialsResponse{
+		Headers:    v.Headers,
+		Expires:    expires,
+		Extensions: extensions,
+	}
+	return nil
 }
 
 func (resp GetCredentialsResponse) MarshalJSON() ([]byte, error) {
@@ -49,5 +254,9 @@ func (resp GetCredentialsResponse) MarshalJSON() ([]byte, error) {
 		expires := resp.Expires.Format(time.RFC3339)
 		v.Expires = &expires
 	}
-	return json.Marshal(v)
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendFields(data, resp.Extensions, "headers", "expires")
 }
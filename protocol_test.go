@@ -17,6 +17,8 @@ package credentialhelper_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -71,6 +73,10 @@ func TestParseGetCredentialsRequestFromStringWithExtraFields(t *testing.T) {
 	if diff := cmp.Diff(
 		credentialhelper.GetCredentialsRequest{
 			URI: "grpcs://example.com",
+			Extensions: map[string]json.RawMessage{
+				"foo": json.RawMessage(`1`),
+				"bar": json.RawMessage(`2`),
+			},
 		},
 		request); diff != "" {
 		t.Errorf("(-want +got):\n%s", diff)
@@ -119,6 +125,10 @@ func TestParseGetCredentialsResponseFromStringWithExtraFields(t *testing.T) {
 				"foo": {"1"},
 				"bar": {"2"},
 			},
+			Extensions: map[string]json.RawMessage{
+				"foo": json.RawMessage(`1`),
+				"bar": json.RawMessage(`2`),
+			},
 		},
 		response1); diff != "" {
 		t.Errorf("(-want +got):\n%s", diff)
@@ -179,3 +189,255 @@ func TestMarshalExpiresTimeWithRFC3339(t *testing.T) {
 		t.Fatalf("got %q; want %q", got, want)
 	}
 }
+
+func TestValidateGetCredentialsResponse(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	for _, tc := range []struct {
+		name     string
+		response credentialhelper.GetCredentialsResponse
+		wantErr  string
+	}{
+		{
+			name:     "empty",
+			response: credentialhelper.GetCredentialsResponse{},
+		},
+		{
+			name: "valid",
+			response: credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{
+					"Authorization":   {"Bearer abc"},
+					"x-custom_header": {"a\tb", "c"},
+				},
+				Expires: &future,
+			},
+		},
+		{
+			name: "space in name",
+			response: credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{"Bad Name": {"value"}},
+			},
+			wantErr: `header "Bad Name": name is not a valid token`,
+		},
+		{
+			name: "colon in name",
+			response: credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{"Bad:Name": {"value"}},
+			},
+			wantErr: `header "Bad:Name": name is not a valid token`,
+		},
+		{
+			name: "no values",
+			response: credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{"Authorization": {}},
+			},
+			wantErr: `header "Authorization": no values`,
+		},
+		{
+			name: "empty value",
+			response: credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{"Authorization": {"a", ""}},
+			},
+			wantErr: `header "Authorization": value 1 is empty`,
+		},
+		{
+			name: "newline in value",
+			response: credentialhelper.GetCredentialsResponse{
+				Headers: map[string][]string{"Authorization": {"a\nb"}},
+			},
+			wantErr: `header "Authorization": value 0 contains control character '\n' at offset 1`,
+		},
+		{
+			name: "expired",
+			response: credentialhelper.GetCredentialsResponse{
+				Expires: &past,
+			},
+			wantErr: "credentials expired at",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.response.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, credentialhelper.ErrInvalidResponse) {
+				t.Errorf("Validate() = %v, want error wrapping ErrInvalidResponse", err)
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetCredentialsResponseAuthorizationCredentials(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		headers map[string][]string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "matching scheme",
+			headers: map[string][]string{"Authorization": {"Bearer token"}},
+			want:    "token",
+		},
+		{
+			name:    "case-insensitive",
+			headers: map[string][]string{"authorization": {"bearer  token "}},
+			want:    "token",
+		},
+		{
+			name:    "other scheme",
+			headers: map[string][]string{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			wantErr: `authorization scheme "Basic" is not supported, only Bearer is`,
+		},
+		{
+			name:    "empty credentials",
+			headers: map[string][]string{"Authorization": {"Bearer "}},
+			wantErr: "empty Bearer credentials",
+		},
+		{
+			name:    "other header",
+			headers: map[string][]string{"Authorization": {"Bearer token"}, "X-Foo": {"bar"}},
+			wantErr: `header "X-Foo" is not supported`,
+		},
+		{
+			name:    "several values",
+			headers: map[string][]string{"Authorization": {"Bearer a", "Bearer b"}},
+			wantErr: "expected a single Authorization header, got 2",
+		},
+		{
+			name:    "no headers",
+			wantErr: "expected a single Authorization header, got 0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			response := credentialhelper.GetCredentialsResponse{Headers: tc.headers}
+			got, err := response.AuthorizationCredentials("Bearer")
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("AuthorizationCredentials() = %q, %v, want error %q", got, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("AuthorizationCredentials() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseGetCredentialsResponseExpiryParsing(t *testing.T) {
+	utc := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	utcMillis := time.Date(2001, 2, 3, 4, 5, 6, 123000000, time.UTC)
+
+	for _, tc := range []struct {
+		expires string
+		strict  *time.Time
+		lenient *time.Time
+	}{
+		{`null`, nil, nil},
+		{`"2001-02-03T04:05:06Z"`, &utc, &utc},
+		{`"2001-02-03T05:05:06+01:00"`, &utc, &utc},
+		{`"2001-02-03T04:05:06.123Z"`, &utcMillis, &utcMillis},
+		{`"2001-02-03t04:05:06z"`, nil, &utc},
+		{`"2001-02-03T04:05:06z"`, nil, &utc},
+		{`"2001-02-03 04:05:06Z"`, nil, &utc},
+		{`"2001-02-03T05:05:06+0100"`, nil, &utc},
+		{`981173106`, nil, &utc},
+		{`"981173106"`, nil, &utc},
+		{`981173106.123`, nil, &utcMillis},
+		{`"2001-02-03T04:05:06"`, nil, nil},
+		{`"2001-02-03T04:05Z"`, nil, nil},
+		{`"2001-02-03"`, nil, nil},
+		{`"foo"`, nil, nil},
+		{`true`, nil, nil},
+	} {
+		t.Run(tc.expires, func(t *testing.T) {
+			data := []byte(`{"expires": ` + tc.expires + `}`)
+			isNull := tc.expires == "null"
+
+			for _, mode := range []struct {
+				name    string
+				mode    credentialhelper.ExpiryParsing
+				want    *time.Time
+				wantErr bool
+			}{
+				{"strict", credentialhelper.ExpiryParsingStrict, tc.strict, tc.strict == nil && !isNull},
+				{"lenient", credentialhelper.ExpiryParsingLenient, tc.lenient, tc.lenient == nil && !isNull},
+			} {
+				response, err := credentialhelper.UnmarshalGetCredentialsResponse(data, mode.mode)
+				if mode.wantErr {
+					if err == nil {
+						t.Errorf("%s: expected error, got %v", mode.name, response.Expires)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%s: unexpected error: %v", mode.name, err)
+					continue
+				}
+				if diff := cmp.Diff(mode.want, response.Expires); diff != "" {
+					t.Errorf("%s: (-want +got):\n%s", mode.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestParseGetCredentialsResponseIsStrictByDefault(t *testing.T) {
+	var response credentialhelper.GetCredentialsResponse
+	if err := json.Unmarshal(
+		[]byte(`{"expires": 981173106}`),
+		&response); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestMarshalGetCredentialsRequestWithExtensions(t *testing.T) {
+	var request credentialhelper.GetCredentialsRequest
+	if err := json.Unmarshal(
+		[]byte(`{"uri": "grpcs://example.com", "zzz": {"nested": [1, 2]}, "aaa": "x"}`),
+		&request); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte(`{"uri":"grpcs://example.com","aaa":"x","zzz":{"nested":[1,2]}}`)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %q; want %q", got, want)
+	}
+}
+
+func TestMarshalGetCredentialsResponseWithExtensions(t *testing.T) {
+	expires := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	resp := credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{"a": {"b"}},
+		Expires: &expires,
+		Extensions: map[string]json.RawMessage{
+			"future": json.RawMessage(`{"x": true}`),
+			// Extensions cannot override fields of the protocol.
+			"headers": json.RawMessage(`{}`),
+		},
+	}
+	got, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte(`{"headers":{"a":["b"]},"expires":"2001-02-03T04:05:06Z","future":{"x":true}}`)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %q; want %q", got, want)
+	}
+}
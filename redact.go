@@ -0,0 +1,181 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shortHash returns a short fingerprint of data, allowing to tell secrets
+// apart without revealing them.
+func shortHash(data ...string) string {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write([]byte(d))
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// redactValue returns a placeholder describing value without revealing it.
+func redactValue(value string) string {
+	return fmt.Sprintf("[redacted, %d bytes, %s]", len(value), shortHash(value))
+}
+
+// redactValues returns a placeholder describing values without revealing
+// them.
+func redactValues(values []string) string {
+	return fmt.Sprintf("%d value(s), %s", len(values), shortHash(values...))
+}
+
+// formatRedacted implements [fmt.Formatter] for types whose String method
+// redacts secrets, so that no verb or flag prints them.
+func formatRedacted(f fmt.State, verb rune, typeName string, s string) {
+	switch verb {
+	case 'v', 's':
+		fmt.Fprint(f, s)
+	case 'q':
+		fmt.Fprint(f, strconv.Quote(s))
+	default:
+		fmt.Fprintf(f, "%%!%c(%s=redacted)", verb, typeName)
+	}
+}
+
+// sortedHeaderNames returns the names of headers in order.
+func sortedHeaderNames(headers map[string][]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns a description of the response with all header values
+// redacted. Use [GetCredentialsResponse.UnredactedString] to reveal them.
+func (resp GetCredentialsResponse) String() string {
+	var b strings.Builder
+	b.WriteString("GetCredentialsResponse{headers: [")
+	for i, name := range sortedHeaderNames(resp.Headers) {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(redactValues(resp.Headers[name]))
+	}
+	b.WriteString("]")
+	if resp.Expires != nil {
+		b.WriteString(", expires: ")
+		b.WriteString(resp.Expires.Format(time.RFC3339))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// UnredactedString returns a description of the response including all
+// header values.
+//
+// The result contains secrets; never log it.
+func (resp GetCredentialsResponse) UnredactedString() string {
+	var b strings.Builder
+	b.WriteString("GetCredentialsResponse{headers: [")
+	for i, name := range sortedHeaderNames(resp.Headers) {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strconv.Quote(strings.Join(resp.Headers[name], ", ")))
+	}
+	b.WriteString("]")
+	if resp.Expires != nil {
+		b.WriteString(", expires: ")
+		b.WriteString(resp.Expires.Format(time.RFC3339))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Format implements [fmt.Formatter] so that formatting the response with any
+// verb redacts header values.
+func (resp GetCredentialsResponse) Format(f fmt.State, verb rune) {
+	formatRedacted(f, verb, "GetCredentialsResponse", resp.String())
+}
+
+// LogValue implements [slog.LogValuer] with all header values redacted.
+func (resp GetCredentialsResponse) LogValue() slog.Value {
+	headers := make([]slog.Attr, 0, len(resp.Headers))
+	for _, name := range sortedHeaderNames(resp.Headers) {
+		headers = append(headers, slog.String(name, redactValues(resp.Headers[name])))
+	}
+
+	attrs := []slog.Attr{
+		{Key: "headers", Value: slog.GroupValue(headers...)},
+	}
+	if resp.Expires != nil {
+		attrs = append(attrs, slog.Time("expires", *resp.Expires))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// String returns the same as [HelperError.Error].
+func (e *HelperError) String() string {
+	return e.Error()
+}
+
+// Format implements [fmt.Formatter]. The `%+v` verb adds details about the
+// invocation, with stderr redacted as it might contain secrets.
+func (e *HelperError) Format(f fmt.State, verb rune) {
+	s := e.Error()
+	if verb == 'v' && f.Flag('+') {
+		s = fmt.Sprintf("%s (command: %q, phase: %v, exit code: %d, elapsed: %v, stderr: %s)", s, e.Command, e.Phase, e.ExitCode, e.Elapsed, e.redactedStderr())
+	}
+	formatRedacted(f, verb, "HelperError", s)
+}
+
+// LogValue implements [slog.LogValuer] with stderr redacted.
+func (e *HelperError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("error", e.Error()),
+		slog.String("path", e.Path),
+		slog.String("command", e.Command),
+		slog.String("phase", e.Phase.String()),
+		slog.Int("exit_code", e.ExitCode),
+		slog.Duration("elapsed", e.Elapsed),
+		slog.String("stderr", e.redactedStderr()))
+}
+
+// UnredactedStderr returns what the credential helper wrote to stderr.
+//
+// The result might contain secrets; never log it.
+func (e *HelperError) UnredactedStderr() string {
+	return string(e.Stderr)
+}
+
+func (e *HelperError) redactedStderr() string {
+	s := redactValue(string(e.Stderr))
+	if e.StderrTruncated {
+		s += " (truncated)"
+	}
+	return s
+}
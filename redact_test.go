@@ -0,0 +1,102 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/EngFlow/credential-helper-go"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "Bearer s3cr3t-t0k3n"
+
+func secretResponse() credentialhelper.GetCredentialsResponse {
+	expires := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return credentialhelper.GetCredentialsResponse{
+		Headers: map[string][]string{
+			"Authorization": {secret},
+			"X-Other":       {"a", "b"},
+		},
+		Expires: &expires,
+	}
+}
+
+func TestGetCredentialsResponse_Redacted(t *testing.T) {
+	response := secretResponse()
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		for _, value := range []any{response, &response} {
+			got := fmt.Sprintf(format, value)
+			assert.NotContains(t, got, "s3cr3t", format)
+		}
+	}
+
+	assert.Regexp(
+		t,
+		`^GetCredentialsResponse\{headers: \[Authorization: 1 value\(s\), sha256:[0-9a-f]{8}, X-Other: 2 value\(s\), sha256:[0-9a-f]{8}\], expires: 2001-02-03T04:05:06Z\}$`,
+		response.String())
+}
+
+func TestGetCredentialsResponse_UnredactedString(t *testing.T) {
+	response := secretResponse()
+	assert.Equal(
+		t,
+		`GetCredentialsResponse{headers: [Authorization: "Bearer s3cr3t-t0k3n", X-Other: "a, b"], expires: 2001-02-03T04:05:06Z}`,
+		response.UnredactedString())
+}
+
+func TestGetCredentialsResponse_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("fetched credentials", "response", secretResponse())
+
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.Contains(t, buf.String(), `"response":{"headers":{"Authorization":"1 value(s), sha256:`)
+	assert.Contains(t, buf.String(), `"expires":"2001-02-03T04:05:06Z"`)
+}
+
+func TestHelperError_Redacted(t *testing.T) {
+	err := error(&credentialhelper.HelperError{
+		Path:     "/path/to/helper",
+		Command:  "get",
+		Phase:    credentialhelper.PhaseExit,
+		ExitCode: 1,
+		Stderr:   []byte("failed to refresh " + secret),
+		Err:      errors.New("exit status 1"),
+	})
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q"} {
+		assert.NotContains(t, fmt.Sprintf(format, err), "s3cr3t", format)
+	}
+	assert.Contains(t, fmt.Sprintf("%+v", err), `command: "get", phase: exit, exit code: 1`)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("credential helper failed", "error", err)
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.Contains(t, buf.String(), `"exit_code":1`)
+
+	var helperErr *credentialhelper.HelperError
+	if assert.True(t, errors.As(err, &helperErr)) {
+		assert.Contains(t, helperErr.UnredactedStderr(), "s3cr3t")
+	}
+}
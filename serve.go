@@ -0,0 +1,71 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"encoding/json"
+)
+
+// The `serve` command keeps a credential helper running to answer many
+// requests without spawning a process for each of them.
+//
+// All messages are JSON objects on a single line. Once started, the
+// credential helper writes a [serveHello] to stdout. Afterwards, it reads
+// [serveRequest]s from stdin until it is closed, and answers each of them with
+// a [serveResponse] on stdout. Requests may be answered out of order.
+//
+// Every request represents a regular invocation of the credential helper, and
+// its response carries what that invocation would have written to stdout and
+// stderr as well as its exit code.
+const (
+	serveCommand = "serve"
+
+	serveProtocolVersion = 1
+)
+
+// serveHello is written by the credential helper once it is ready to accept
+// requests.
+type serveHello struct {
+	Version int `json:"version"`
+}
+
+// serveRequest represents a single invocation of the credential helper.
+type serveRequest struct {
+	// ID identifies the request. It is copied to the response.
+	ID uint64 `json:"id"`
+
+	// Args are the arguments of the invocation, starting with the command
+	// (e.g., `get`).
+	Args []string `json:"args"`
+
+	// Stdin is the input of the invocation.
+	Stdin json.RawMessage `json:"stdin,omitempty"`
+}
+
+// serveResponse represents the result of a single invocation of the
+// credential helper.
+type serveResponse struct {
+	// ID is the ID of the request.
+	ID uint64 `json:"id"`
+
+	// ExitCode is the exit code of the invocation.
+	ExitCode int `json:"exit_code"`
+
+	// Stdout is the output of the invocation, if any.
+	Stdout json.RawMessage `json:"stdout,omitempty"`
+
+	// Stderr is the diagnostic output of the invocation, if any.
+	Stderr string `json:"stderr,omitempty"`
+}
@@ -0,0 +1,3 @@
+#!/usr/bin/env bash
+
+echo '{"headers": {}, "expires": 981173106}'
@@ -0,0 +1,15 @@
+#!/usr/bin/env bash
+#
+# Ignores SIGTERM, recording that it received one. Writes the PID of a
+# long-running child process, which ignores SIGTERM as well, to `pid` and
+# SIGTERMs it received to `term` in the directory given as the last argument.
+
+dir="${@: -1}"
+
+trap 'echo TERM >> "$dir/term"' TERM
+(trap '' TERM; exec sleep 60) &
+child="$!"
+echo "$child" > "$dir/pid"
+while kill -0 "$child" 2> /dev/null; do
+  wait "$child"
+done
@@ -0,0 +1,3 @@
+#!/usr/bin/env bash
+
+echo '{"headers": {"Bad Name": ["value"], "Injected": ["secret\r\nX-Evil: 1"]}}'
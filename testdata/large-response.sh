@@ -0,0 +1,3 @@
+#!/usr/bin/env bash
+
+printf '{"headers": {"foo": ["%s"]}}\n' "$(head -c 4096 /dev/zero | tr '\0' 'x')"
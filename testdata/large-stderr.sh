@@ -0,0 +1,6 @@
+#!/usr/bin/env bash
+
+for i in $(seq 1 1000); do
+  echo "line $i" 1>&2
+done
+exit 1
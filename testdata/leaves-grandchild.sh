@@ -0,0 +1,8 @@
+#!/usr/bin/env bash
+#
+# Responds, but leaves behind a child process holding on to stdout. Its PID is
+# written to the file given as the last argument.
+
+sleep 10 &
+echo "$!" > "${@: -1}"
+echo "{}"
@@ -0,0 +1,8 @@
+#!/usr/bin/env bash
+#
+# Spawns a long-running child process, writes its PID to the file given as the
+# last argument and waits for it.
+
+sleep 60 &
+echo "$!" > "${@: -1}"
+wait
@@ -0,0 +1,115 @@
+// Copyright 2025 EngFlow, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialhelper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrInvalidResponse is wrapped by the errors returned from
+// [GetCredentialsResponse.Validate].
+var ErrInvalidResponse = errors.New("invalid response")
+
+// Validate checks that the response is well-formed, i.e., that
+//
+//   - all header names are tokens as defined in RFC 7230,
+//   - all headers have at least one value,
+//   - no header value is empty or contains control characters (e.g., CR or
+//     LF), and
+//   - the credentials did not expire yet.
+//
+// The returned error wraps [ErrInvalidResponse] and describes every problem,
+// naming the offending headers.
+func (resp GetCredentialsResponse) Validate() error {
+	if problems := responseProblems(&resp, time.Now()); len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, errors.Join(problems...))
+	}
+	return nil
+}
+
+// responseProblems returns everything wrong with response, assuming the
+// current time is now.
+func responseProblems(response *GetCredentialsResponse, now time.Time) []error {
+	var problems []error
+
+	names := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !isToken(name) {
+			problems = append(problems, fmt.Errorf("header %q: name is not a valid token", name))
+		}
+
+		values := response.Headers[name]
+		if len(values) == 0 {
+			problems = append(problems, fmt.Errorf("header %q: no values", name))
+		}
+		for i, value := range values {
+			if value == "" {
+				problems = append(problems, fmt.Errorf("header %q: value %d is empty", name, i))
+			} else if j := indexControlCharacter(value); j >= 0 {
+				problems = append(problems, fmt.Errorf("header %q: value %d contains control character %q at offset %d", name, i, value[j], j))
+			}
+		}
+	}
+
+	if response.Expires != nil && !response.Expires.After(now) {
+		problems = append(problems, fmt.Errorf("credentials expired at %v", response.Expires.Format(time.RFC3339)))
+	}
+
+	return problems
+}
+
+// isToken returns whether s is a token as defined in RFC 7230, section 3.2.6.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	switch c {
+	case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '^', '_', '`', '|', '~':
+		return true
+	}
+	return false
+}
+
+// indexControlCharacter returns the index of the first control character in
+// s other than horizontal tab, or -1 if there is none.
+func indexControlCharacter(s string) int {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return i
+		}
+	}
+	return -1
+}